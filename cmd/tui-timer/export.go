@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/and1truong/tui-timer/internal/export"
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/stats"
+)
+
+// runExport implements `tui-timer export`: it writes the session history
+// in a format other tools can import.
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tui-timer export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "csv", "output format: "+strings.Join(export.Formats, ", "))
+	since := fs.String("since", "", "first day to include (YYYY-MM-DD)")
+	until := fs.String("until", "", "last day to include (YYYY-MM-DD)")
+	task := fs.String("task", "", "only sessions spent on this task")
+	breaks := fs.Bool("breaks", false, "include breaks")
+	file := fs.String("file", "", "history file (default: data dir sessions.jsonl)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if !slices.Contains(export.Formats, *format) {
+		fmt.Fprintf(stderr, "export: unknown format %q (want %s)\n", *format, strings.Join(export.Formats, ", "))
+		return 2
+	}
+	from, err := parseDay(*since)
+	if err != nil {
+		fmt.Fprintf(stderr, "export: --since: %v\n", err)
+		return 2
+	}
+	to, err := parseDay(*until)
+	if err != nil {
+		fmt.Fprintf(stderr, "export: --until: %v\n", err)
+		return 2
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1) // inclusive
+	}
+
+	path := *file
+	if path == "" {
+		if path, err = history.DefaultPath(); err != nil {
+			fmt.Fprintf(stderr, "export: %v\n", err)
+			return 1
+		}
+	}
+	records, bad, err := history.Load(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "export: %v\n", err)
+		return 1
+	}
+	if bad > 0 {
+		fmt.Fprintf(stderr, "export: skipped %d unreadable lines in %s\n", bad, path)
+	}
+
+	records = stats.Filter(records, from, to)
+	if *task != "" {
+		records = stats.FilterTask(records, *task)
+	}
+	if !*breaks {
+		var work []history.Record
+		for _, r := range records {
+			if r.IsWork() {
+				work = append(work, r)
+			}
+		}
+		records = work
+	}
+
+	if err := export.Write(stdout, *format, records); err != nil {
+		fmt.Fprintf(stderr, "export: %v\n", err)
+		return 1
+	}
+	return 0
+}
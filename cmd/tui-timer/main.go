@@ -5,13 +5,28 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/and1truong/tui-timer/internal/clock"
 	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/history"
 	"github.com/and1truong/tui-timer/internal/logger"
+	"github.com/and1truong/tui-timer/internal/notify"
 	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/state"
+	"github.com/and1truong/tui-timer/internal/tasks"
+	"github.com/and1truong/tui-timer/internal/timer"
 	"github.com/and1truong/tui-timer/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stats":
+			os.Exit(runStats(os.Args[2:], os.Stdout, os.Stderr))
+		case "export":
+			os.Exit(runExport(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "config: %v\n", err)
@@ -23,20 +38,129 @@ func main() {
 		os.Exit(1)
 	}
 
-	log, err := logger.New()
+	logOpts, err := cfg.Log.Options()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+		os.Exit(1)
+	}
+	log, err := logger.New(logOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer log.Close()
 
-	player := sound.NewMacPlayer()
+	player, err := sound.New(cfg.Sounds.Backend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sound: %v\n", err)
+		os.Exit(1)
+	}
+	ambient := newAmbient(cfg.Ambient, player, log)
+	player = sound.WithTones(player, cfg.Sounds.Chime)
 
-	model := ui.NewModel(cfg, player, log)
+	store, err := state.NewStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "state: %v\n", err)
+		os.Exit(1)
+	}
+
+	historyPath, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "history: %v\n", err)
+		os.Exit(1)
+	}
+	sessions := history.NewStore(historyPath)
+
+	var taskSource tasks.Source
+	var taskwarrior *tasks.Taskwarrior
+	switch {
+	case cfg.Taskwarrior.Enabled:
+		taskwarrior = tasks.NewTaskwarrior(cfg.Taskwarrior.Filter)
+		taskSource = taskwarrior
+	case cfg.TodoTxt != "":
+		taskSource = tasks.NewTodoTxt(cfg.TodoTxt)
+	default:
+		tasksPath, err := tasks.DefaultPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tasks: %v\n", err)
+			os.Exit(1)
+		}
+		taskSource = tasks.NewStore(tasksPath)
+	}
+
+	model := ui.NewModel(cfg, player, log, clock.Real{}).
+		WithHistory(sessions).
+		WithTasks(taskSource)
+	if taskwarrior != nil {
+		model.Engine().Subscribe(taskwarrior.Handler(func(err error) {
+			log.Error("Taskwarrior: %v", err)
+		}))
+	}
+
+	if ambient != nil {
+		model.Engine().Subscribe(notify.Ambient(ambient))
+	}
+
+	// Standalone timers and deadline plans leave the Pomodoro session alone.
+	if cfg.Countdown == 0 && !cfg.Stopwatch && cfg.Until.IsZero() {
+		model = model.WithStateStore(store)
+		if cfg.Fresh {
+			if err := store.Clear(); err != nil {
+				fmt.Fprintf(os.Stderr, "state: %v\n", err)
+			}
+		} else if snap, ok, err := store.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "state: %v (starting fresh)\n", err)
+		} else if ok {
+			model.Restore(snap)
+		}
+	}
+
+	if e := model.Engine(); ambient != nil && e.State == timer.StateRunning && e.Mode == timer.ModeWork {
+		ambient.Play()
+	}
+	if taskwarrior != nil {
+		if err := taskwarrior.Resume(model.Engine()); err != nil {
+			log.Error("Taskwarrior: %v", err)
+		}
+	}
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	if ambient != nil {
+		ambient.Stop()
+	}
+	if taskwarrior != nil {
+		if err := taskwarrior.Stop(); err != nil {
+			log.Error("Taskwarrior: %v", err)
+		}
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// newAmbient returns the background sound configured by cfg, or nil if it
+// is disabled or cannot be played. Problems are logged rather than fatal.
+func newAmbient(cfg config.AmbientConfig, player sound.Player, log *logger.Logger) *sound.Ambient {
+	if !cfg.Enabled {
+		return nil
+	}
+	streamer, ok := player.(sound.Streamer)
+	if !ok {
+		log.Warn("Ambient: the sound backend cannot stream audio")
+		return nil
+	}
+	src, rate, err := cfg.Source()
+	if err != nil {
+		log.Warn("Ambient: %v", err)
+		return nil
+	}
+	a := sound.NewAmbient(streamer, src, rate)
+	a.Volume = cfg.Volume
+	a.Fade = cfg.Fade
+	a.OnError = func(err error) {
+		log.Error("Ambient: %v", err)
+	}
+	return a
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/stats"
+)
+
+// runStats implements `tui-timer stats`: it summarises the session history
+// without starting the TUI.
+func runStats(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tui-timer stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	by := fs.String("by", "day", "group by day, week, month or task")
+	since := fs.String("since", "", "first day to include (YYYY-MM-DD)")
+	until := fs.String("until", "", "last day to include (YYYY-MM-DD)")
+	format := fs.String("format", "table", "output format: table or json")
+	file := fs.String("file", "", "history file (default: data dir sessions.jsonl)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var period stats.Period
+	if *by != "task" {
+		p, err := stats.ParsePeriod(*by)
+		if err != nil {
+			fmt.Fprintf(stderr, "stats: %v\n", err)
+			return 2
+		}
+		period = p
+	}
+	from, err := parseDay(*since)
+	if err != nil {
+		fmt.Fprintf(stderr, "stats: --since: %v\n", err)
+		return 2
+	}
+	to, err := parseDay(*until)
+	if err != nil {
+		fmt.Fprintf(stderr, "stats: --until: %v\n", err)
+		return 2
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1) // inclusive
+	}
+
+	path := *file
+	if path == "" {
+		if path, err = history.DefaultPath(); err != nil {
+			fmt.Fprintf(stderr, "stats: %v\n", err)
+			return 1
+		}
+	}
+	records, bad, err := history.Load(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "stats: %v\n", err)
+		return 1
+	}
+	if bad > 0 {
+		fmt.Fprintf(stderr, "stats: skipped %d unreadable lines in %s\n", bad, path)
+	}
+
+	records = stats.Filter(records, from, to)
+	var rows []stats.Summary
+	heading := "period"
+	if period == "" {
+		rows, heading = stats.ByTask(records), "task"
+	} else {
+		rows = stats.Group(records, period, time.Local)
+	}
+	total := stats.Total(records)
+
+	switch *format {
+	case "table":
+		err = stats.WriteTable(stdout, heading, rows, total)
+	case "json":
+		err = stats.WriteJSON(stdout, rows, total)
+	default:
+		fmt.Fprintf(stderr, "stats: unknown format %q (want table or json)\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "stats: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
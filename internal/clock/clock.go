@@ -0,0 +1,17 @@
+// Package clock abstracts the passage of time so that the timer engine and
+// the UI can be driven deterministically in tests.
+package clock
+
+import "time"
+
+// Clock tells the current time and schedules wake-ups.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is a Clock backed by the system clock.
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,79 @@
+// Package clocktest provides a manually driven Clock for tests.
+package clocktest
+
+import (
+	"sync"
+	"time"
+)
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// Fake is a Clock whose time only moves when Advance or Set is called.
+// It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+// NewFake returns a Fake clock set to t.
+func NewFake(t time.Time) *Fake {
+	return &Fake{now: t}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	at := f.now.Add(d)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{at: at, ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing any due After channels.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	t := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(t)
+}
+
+// Set moves the clock to t, firing any due After channels.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = t
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if !w.at.After(t) {
+			w.ch <- t
+			continue
+		}
+		pending = append(pending, w)
+	}
+	f.waiters = pending
+}
+
+// Waiters returns the number of After channels that have not fired yet.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
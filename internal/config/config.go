@@ -5,32 +5,296 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/and1truong/tui-timer/internal/logger"
+	"github.com/and1truong/tui-timer/internal/message"
+	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/timer"
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	appName    = "tui-timer"
 	configFile = "config.yaml"
+	logFile    = "log.txt"
 )
 
 type SoundsConfig struct {
 	Tick   bool `yaml:"tick"`
 	Finish bool `yaml:"finish"`
 	Break  bool `yaml:"break"`
+
+	// Backend picks the sound player: auto, mac, linux or none.
+	Backend string `yaml:"backend"`
+
+	// Beep is the chime played for beeps; empty uses sound.DefaultChime.
+	Beep  []ToneConfig `yaml:"beep,omitempty"`
+	Chime sound.Chime  `yaml:"-"`
+
+	// Pack names a sound pack installed in DataDir/sounds, or the path to
+	// one. Files maps sound events to audio files and overrides the pack;
+	// events without a file beep.
+	Pack  string            `yaml:"pack,omitempty"`
+	Files map[string]string `yaml:"files,omitempty"`
+	Paths map[string]string `yaml:"-"` // Pack and Files as absolute paths
+
+	// WarningBefore plays the warning sound when a session has that long
+	// left.
+	WarningBeforeStr string        `yaml:"warning_before,omitempty"`
+	WarningBefore    time.Duration `yaml:"-"`
 }
 
+// paths resolves the pack and the per-event files. Relative files are
+// relative to the config directory.
+func (c SoundsConfig) paths() (map[string]string, error) {
+	paths := make(map[string]string)
+	if c.Pack != "" {
+		p, err := loadPack(c.Pack)
+		if err != nil {
+			return nil, err
+		}
+		for event, file := range p.Files {
+			paths[event] = file
+		}
+	}
+	for event, file := range c.Files {
+		file, err := expandHome(file)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(file) {
+			dir, err := configDir()
+			if err != nil {
+				return nil, err
+			}
+			file = filepath.Join(dir, file)
+		}
+		if err := sound.CheckFile(event, file); err != nil {
+			return nil, err
+		}
+		paths[event] = file
+	}
+	return paths, nil
+}
+
+// SoundPacksDir returns the directory sound packs are installed in.
+func SoundPacksDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sounds"), nil
+}
+
+// loadPack loads the pack named name from SoundPacksDir, or from the
+// directory name when it is a path.
+func loadPack(name string) (*sound.Pack, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.HasPrefix(name, "~") {
+		dir, err := expandHome(name)
+		if err != nil {
+			return nil, err
+		}
+		return sound.LoadPack(dir)
+	}
+	root, err := SoundPacksDir()
+	if err != nil {
+		return nil, err
+	}
+	p, err := sound.LoadPack(filepath.Join(root, name))
+	if os.IsNotExist(err) {
+		err = fmt.Errorf("sound pack %q not found in %s", name, root)
+		if packs, _ := sound.ListPacks(root); len(packs) > 0 {
+			names := make([]string, len(packs))
+			for i, p := range packs {
+				names[i] = filepath.Base(p.Dir)
+			}
+			err = fmt.Errorf("%w (installed: %s)", err, strings.Join(names, ", "))
+		}
+	}
+	return p, err
+}
+
+// ToneConfig is one note of a chime. A zero frequency is a rest.
+type ToneConfig struct {
+	Frequency float64 `yaml:"frequency"`
+	Duration  string  `yaml:"duration"`
+	Attack    string  `yaml:"attack,omitempty"`
+	Release   string  `yaml:"release,omitempty"`
+	Volume    float64 `yaml:"volume,omitempty"` // 0 to 1, default 0.5
+}
+
+// chime parses the beep tones.
+func (c SoundsConfig) chime() (sound.Chime, error) {
+	if len(c.Beep) == 0 {
+		return sound.DefaultChime, nil
+	}
+	chime := make(sound.Chime, 0, len(c.Beep))
+	for i, tc := range c.Beep {
+		t := sound.Tone{Freq: tc.Frequency, Volume: tc.Volume}
+		if t.Volume == 0 {
+			t.Volume = 0.5
+		}
+		if t.Freq < 0 || t.Volume < 0 || t.Volume > 1 {
+			return nil, fmt.Errorf("tone %d: frequency must not be negative and volume must be between 0 and 1", i+1)
+		}
+		for _, d := range []struct {
+			name string
+			s    string
+			dst  *time.Duration
+		}{{"duration", tc.Duration, &t.Duration}, {"attack", tc.Attack, &t.Attack}, {"release", tc.Release, &t.Release}} {
+			if d.s == "" {
+				continue
+			}
+			v, err := time.ParseDuration(d.s)
+			if err != nil {
+				return nil, fmt.Errorf("tone %d: invalid %s: %w", i+1, d.name, err)
+			}
+			*d.dst = v
+		}
+		if t.Duration <= 0 {
+			return nil, fmt.Errorf("tone %d: duration must be positive", i+1)
+		}
+		chime = append(chime, t)
+	}
+	return chime, nil
+}
+
+// AmbientConfig plays background noise, or a looping file, during work
+// sessions.
+type AmbientConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Noise   string  `yaml:"noise"`          // white, pink or brown
+	File    string  `yaml:"file,omitempty"` // 16-bit PCM WAV looped instead of noise
+	Volume  float64 `yaml:"volume"`         // 0 to 1
+	FadeStr string  `yaml:"fade"`
+
+	Fade time.Duration `yaml:"-"`
+}
+
+// validate checks the options that are not parsed elsewhere.
+func (c AmbientConfig) validate() error {
+	if _, err := sound.ParseNoise(c.Noise); err != nil && c.File == "" {
+		return err
+	}
+	if c.Volume < 0 || c.Volume > 1 {
+		return fmt.Errorf("volume must be between 0 and 1")
+	}
+	return nil
+}
+
+// Source returns the sound to play and its sample rate.
+func (c AmbientConfig) Source() (sound.Source, int, error) {
+	if c.File != "" {
+		return sound.LoadLoop(c.File)
+	}
+	color, err := sound.ParseNoise(c.Noise)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sound.NewNoise(color, uint64(time.Now().UnixNano())), sound.SampleRate, nil
+}
+
+// VoiceMessages are text/template templates executed with message.Data.
 type VoiceMessages struct {
 	WorkDone  string `yaml:"work_done"`
 	BreakDone string `yaml:"break_done"`
 	Start     string `yaml:"start"`
 }
 
+// VoiceTemplates are the parsed VoiceMessages.
+type VoiceTemplates struct {
+	WorkDone  *message.Template
+	BreakDone *message.Template
+	Start     *message.Template
+}
+
+// parse parses and checks every message.
+func (m VoiceMessages) parse() (VoiceTemplates, error) {
+	var t VoiceTemplates
+	for _, msg := range []struct {
+		name string
+		text string
+		dst  **message.Template
+	}{{"work_done", m.WorkDone, &t.WorkDone}, {"break_done", m.BreakDone, &t.BreakDone}, {"start", m.Start, &t.Start}} {
+		tmpl, err := message.Parse(msg.name, msg.text)
+		if err != nil {
+			return t, fmt.Errorf("invalid voice.messages.%s: %w", msg.name, err)
+		}
+		*msg.dst = tmpl
+	}
+	return t, nil
+}
+
 type VoiceConfig struct {
-	Enabled  bool          `yaml:"enabled"`
-	Voice    string        `yaml:"voice"`
-	Messages VoiceMessages `yaml:"messages"`
+	Enabled   bool           `yaml:"enabled"`
+	Voice     string         `yaml:"voice"`
+	Messages  VoiceMessages  `yaml:"messages"`
+	Templates VoiceTemplates `yaml:"-"`
+}
+
+// SequenceStep is one entry of a custom session sequence. An entry either
+// describes a single session (Mode, Duration, Label) or groups nested Steps.
+// Repeat runs the entry that many times.
+type SequenceStep struct {
+	Mode     string         `yaml:"mode,omitempty"`
+	Duration string         `yaml:"duration,omitempty"`
+	Label    string         `yaml:"label,omitempty"`
+	Repeat   int            `yaml:"repeat,omitempty"`
+	Steps    []SequenceStep `yaml:"steps,omitempty"`
+}
+
+// BracketConfig maps work lengths up to UpTo to a fixed break.
+type BracketConfig struct {
+	UpTo  string `yaml:"up_to"`
+	Break string `yaml:"break"`
+}
+
+type FlowtimeConfig struct {
+	Enabled    bool            `yaml:"enabled"`
+	BreakRatio float64         `yaml:"break_ratio"`
+	Brackets   []BracketConfig `yaml:"brackets,omitempty"`
+}
+
+// LogConfig controls the application log.
+type LogConfig struct {
+	Level      string `yaml:"level"`          // debug, info, warn or error
+	File       string `yaml:"file,omitempty"` // defaults to log.txt in the data dir
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+	MaxAgeStr  string `yaml:"max_age"`
+
+	MaxAge time.Duration `yaml:"-"`
+}
+
+// Options returns the logger options for c.
+func (c LogConfig) Options() (logger.Options, error) {
+	level, err := logger.ParseLevel(c.Level)
+	if err != nil {
+		return logger.Options{}, err
+	}
+	path := c.File
+	if path == "" {
+		dir, err := DataDir()
+		if err != nil {
+			return logger.Options{}, err
+		}
+		path = filepath.Join(dir, logFile)
+	}
+	return logger.Options{
+		Path:       path,
+		Level:      level,
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxAge:     c.MaxAge,
+		MaxBackups: c.MaxBackups,
+	}, nil
+}
+
+// TaskwarriorConfig makes the task list come from Taskwarrior.
+type TaskwarriorConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Filter  string `yaml:"filter,omitempty"` // e.g. "project:work"
 }
 
 type Config struct {
@@ -39,28 +303,78 @@ type Config struct {
 	LongBreak        time.Duration `yaml:"-"`
 	CyclesBeforeLong int           `yaml:"cycles_before_long"`
 
+	AutoStartBreaks bool          `yaml:"auto_start_breaks"`
+	AutoStartWork   bool          `yaml:"auto_start_work"`
+	AutoStartDelay  time.Duration `yaml:"-"`
+
+	// Sequence replaces the work/short/long cycle when non-empty.
+	Sequence []SequenceStep `yaml:"sequence,omitempty"`
+	Steps    []timer.Step   `yaml:"-"`
+
+	Flowtime   FlowtimeConfig   `yaml:"flowtime"`
+	FlowPolicy timer.FlowPolicy `yaml:"-"`
+
+	// TodoTxt, when set, is the todo.txt file the task list reads and
+	// writes instead of its own tasks.json.
+	TodoTxt string `yaml:"todo_txt,omitempty"`
+
+	Taskwarrior TaskwarriorConfig `yaml:"taskwarrior,omitempty"`
+
+	Log LogConfig `yaml:"log"`
+
 	// YAML string fields for serialization
-	WorkDurationStr string `yaml:"work_duration"`
-	ShortBreakStr   string `yaml:"short_break"`
-	LongBreakStr    string `yaml:"long_break"`
+	WorkDurationStr   string `yaml:"work_duration"`
+	ShortBreakStr     string `yaml:"short_break"`
+	LongBreakStr      string `yaml:"long_break"`
+	AutoStartDelayStr string `yaml:"auto_start_delay"`
+
+	Sounds  SoundsConfig  `yaml:"sounds"`
+	Ambient AmbientConfig `yaml:"ambient"`
+	Voice   VoiceConfig   `yaml:"voice"`
 
-	Sounds SoundsConfig `yaml:"sounds"`
-	Voice  VoiceConfig  `yaml:"voice"`
+	// Runtime-only options set from CLI flags.
+	Fresh     bool          `yaml:"-"`
+	Countdown time.Duration `yaml:"-"`
+	Stopwatch bool          `yaml:"-"`
+	Until     time.Time     `yaml:"-"`
+	FitUntil  bool          `yaml:"-"`
+	Task      string        `yaml:"-"`
 }
 
 func DefaultConfig() *Config {
-	return &Config{
-		WorkDuration:     25 * time.Minute,
-		ShortBreak:       5 * time.Minute,
-		LongBreak:        15 * time.Minute,
-		CyclesBeforeLong: 4,
-		WorkDurationStr:  "25m",
-		ShortBreakStr:    "5m",
-		LongBreakStr:     "15m",
+	cfg := &Config{
+		WorkDuration:      25 * time.Minute,
+		ShortBreak:        5 * time.Minute,
+		LongBreak:         15 * time.Minute,
+		CyclesBeforeLong:  4,
+		AutoStartDelay:    5 * time.Second,
+		WorkDurationStr:   "25m",
+		ShortBreakStr:     "5m",
+		LongBreakStr:      "15m",
+		AutoStartDelayStr: "5s",
+		Flowtime: FlowtimeConfig{
+			BreakRatio: 0.2,
+		},
+		FlowPolicy: timer.FlowPolicy{Ratio: 0.2},
+		Log: LogConfig{
+			Level:      "info",
+			MaxSizeMB:  1,
+			MaxBackups: 3,
+			MaxAgeStr:  "720h",
+			MaxAge:     30 * 24 * time.Hour,
+		},
 		Sounds: SoundsConfig{
-			Tick:   true,
-			Finish: true,
-			Break:  true,
+			Tick:    true,
+			Finish:  true,
+			Break:   true,
+			Backend: "auto",
+			Chime:   sound.DefaultChime,
+		},
+		Ambient: AmbientConfig{
+			Noise:   "brown",
+			Volume:  0.3,
+			FadeStr: "3s",
+			Fade:    3 * time.Second,
 		},
 		Voice: VoiceConfig{
 			Enabled: true,
@@ -72,14 +386,40 @@ func DefaultConfig() *Config {
 			},
 		},
 	}
+	// The default messages are plain text and always parse.
+	cfg.Voice.Templates, _ = cfg.Voice.Messages.parse()
+	return cfg
 }
 
+// configDir returns the directory of the config file, honouring
+// $XDG_CONFIG_HOME and defaulting to ~/.config/tui-timer.
 func configDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns the directory for runtime state, honouring
+// $XDG_STATE_HOME and defaulting to ~/.local/state/tui-timer.
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// DataDir returns the directory for user data such as session history and
+// the log, honouring $XDG_DATA_HOME and defaulting to ~/.local/share/tui-timer.
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// xdgDir returns the app's directory under the base directory named by env,
+// or under the home-relative fallback when env is unset or not absolute.
+func xdgDir(env string, fallback ...string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".config", appName), nil
+	return filepath.Join(append(append([]string{home}, fallback...), appName)...), nil
 }
 
 func ConfigPath() (string, error) {
@@ -117,6 +457,43 @@ func Load() (*Config, error) {
 		return cfg, err
 	}
 
+	if cfg.Steps, err = expandSequence(cfg.Sequence); err != nil {
+		return cfg, fmt.Errorf("invalid sequence: %w", err)
+	}
+
+	if cfg.FlowPolicy, err = cfg.Flowtime.policy(); err != nil {
+		return cfg, fmt.Errorf("invalid flowtime: %w", err)
+	}
+
+	if cfg.Sounds.Chime, err = cfg.Sounds.chime(); err != nil {
+		return cfg, fmt.Errorf("invalid sounds.beep: %w", err)
+	}
+	if cfg.Voice.Templates, err = cfg.Voice.Messages.parse(); err != nil {
+		return cfg, err
+	}
+	if cfg.Sounds.Paths, err = cfg.Sounds.paths(); err != nil {
+		return cfg, fmt.Errorf("invalid sounds: %w", err)
+	}
+	if cfg.Ambient.File, err = expandHome(cfg.Ambient.File); err != nil {
+		return cfg, fmt.Errorf("invalid ambient.file: %w", err)
+	}
+	if err := cfg.Ambient.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid ambient: %w", err)
+	}
+
+	if cfg.TodoTxt, err = expandHome(cfg.TodoTxt); err != nil {
+		return cfg, fmt.Errorf("invalid todo_txt: %w", err)
+	}
+	if cfg.Log.File, err = expandHome(cfg.Log.File); err != nil {
+		return cfg, fmt.Errorf("invalid log.file: %w", err)
+	}
+	if _, err := logger.ParseLevel(cfg.Log.Level); err != nil {
+		return cfg, fmt.Errorf("invalid log.level: %w", err)
+	}
+	if cfg.TodoTxt != "" && cfg.Taskwarrior.Enabled {
+		return cfg, fmt.Errorf("todo_txt and taskwarrior cannot both be used")
+	}
+
 	return cfg, nil
 }
 
@@ -157,9 +534,93 @@ func (c *Config) parseDurations() error {
 			return fmt.Errorf("invalid long_break: %w", err)
 		}
 	}
+	if c.AutoStartDelayStr != "" {
+		c.AutoStartDelay, err = time.ParseDuration(c.AutoStartDelayStr)
+		if err != nil {
+			return fmt.Errorf("invalid auto_start_delay: %w", err)
+		}
+	}
+	if c.Log.MaxAgeStr != "" {
+		c.Log.MaxAge, err = time.ParseDuration(c.Log.MaxAgeStr)
+		if err != nil {
+			return fmt.Errorf("invalid log.max_age: %w", err)
+		}
+	}
+	if c.Sounds.WarningBeforeStr != "" {
+		c.Sounds.WarningBefore, err = time.ParseDuration(c.Sounds.WarningBeforeStr)
+		if err != nil {
+			return fmt.Errorf("invalid sounds.warning_before: %w", err)
+		}
+	}
+	if c.Ambient.FadeStr != "" {
+		c.Ambient.Fade, err = time.ParseDuration(c.Ambient.FadeStr)
+		if err != nil {
+			return fmt.Errorf("invalid ambient.fade: %w", err)
+		}
+	}
 	return nil
 }
 
+// expandSequence flattens config steps, unrolling repeats.
+func expandSequence(entries []SequenceStep) ([]timer.Step, error) {
+	var steps []timer.Step
+	for i, entry := range entries {
+		repeat := entry.Repeat
+		if repeat == 0 {
+			repeat = 1
+		}
+		if repeat < 0 {
+			return nil, fmt.Errorf("step %d: repeat must be positive", i+1)
+		}
+
+		var body []timer.Step
+		if len(entry.Steps) > 0 {
+			nested, err := expandSequence(entry.Steps)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			body = nested
+		} else {
+			mode, err := timer.ParseMode(entry.Mode)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			d, err := time.ParseDuration(entry.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid duration: %w", i+1, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("step %d: duration must be positive", i+1)
+			}
+			body = []timer.Step{{Mode: mode, Duration: d, Label: entry.Label}}
+		}
+
+		for r := 0; r < repeat; r++ {
+			steps = append(steps, body...)
+		}
+	}
+	return steps, nil
+}
+
+func (f FlowtimeConfig) policy() (timer.FlowPolicy, error) {
+	p := timer.FlowPolicy{Ratio: f.BreakRatio}
+	if f.BreakRatio < 0 {
+		return p, fmt.Errorf("break_ratio must not be negative")
+	}
+	for i, b := range f.Brackets {
+		upTo, err := time.ParseDuration(b.UpTo)
+		if err != nil {
+			return p, fmt.Errorf("bracket %d: invalid up_to: %w", i+1, err)
+		}
+		brk, err := time.ParseDuration(b.Break)
+		if err != nil {
+			return p, fmt.Errorf("bracket %d: invalid break: %w", i+1, err)
+		}
+		p.Brackets = append(p.Brackets, timer.Bracket{UpTo: upTo, Break: brk})
+	}
+	return p, nil
+}
+
 // ApplyCLIFlags parses CLI flags and overrides config values.
 func (c *Config) ApplyCLIFlags(args []string) error {
 	fs := flag.NewFlagSet("tui-timer", flag.ContinueOnError)
@@ -168,6 +629,18 @@ func (c *Config) ApplyCLIFlags(args []string) error {
 	shortBreak := fs.String("short-break", "", "short break duration")
 	longBreak := fs.String("long-break", "", "long break duration")
 	voice := fs.String("voice", "", "macOS voice name")
+	countdown := fs.String("countdown", "", "run a single countdown (e.g. 12m)")
+	fs.BoolVar(&c.Stopwatch, "stopwatch", false, "run a stopwatch with laps")
+	until := fs.String("until", "", "count down to a wall-clock time (e.g. 17:30)")
+	fs.BoolVar(&c.FitUntil, "fit", false, "with --until, fit as many Pomodoro cycles as possible")
+	fs.BoolVar(&c.AutoStartBreaks, "auto-start-breaks", c.AutoStartBreaks, "start breaks automatically")
+	fs.BoolVar(&c.AutoStartWork, "auto-start-work", c.AutoStartWork, "start work sessions automatically")
+	fs.BoolVar(&c.Flowtime.Enabled, "flowtime", c.Flowtime.Enabled, "count work up and earn proportional breaks")
+	fs.BoolVar(&c.Fresh, "fresh", false, "discard the saved session and start over")
+	fs.StringVar(&c.Task, "task", "", "task or project the work sessions are spent on")
+	fs.StringVar(&c.Log.File, "log-file", c.Log.File, "write the log to this file")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn or error")
+	ambient := fs.String("ambient", "", "background noise during work: white, pink, brown or off")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -200,6 +673,85 @@ func (c *Config) ApplyCLIFlags(args []string) error {
 	if *voice != "" {
 		c.Voice.Voice = *voice
 	}
+	if *logLevel != "" {
+		if _, err := logger.ParseLevel(*logLevel); err != nil {
+			return fmt.Errorf("invalid --log-level: %w", err)
+		}
+		c.Log.Level = *logLevel
+	}
+	switch *ambient {
+	case "":
+	case "off":
+		c.Ambient.Enabled = false
+	default:
+		if _, err := sound.ParseNoise(*ambient); err != nil {
+			return fmt.Errorf("invalid --ambient: %w", err)
+		}
+		c.Ambient.Enabled = true
+		c.Ambient.Noise = *ambient
+		c.Ambient.File = ""
+	}
+	if *countdown != "" {
+		d, err := time.ParseDuration(*countdown)
+		if err != nil {
+			return fmt.Errorf("invalid --countdown: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid --countdown: must be positive")
+		}
+		c.Countdown = d
+	}
+	if *until != "" {
+		t, err := parseClockTime(*until, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		c.Until = t
+	}
+	if c.FitUntil && c.Until.IsZero() {
+		return fmt.Errorf("--fit requires --until")
+	}
+	modes := 0
+	for _, set := range []bool{c.Countdown > 0, c.Stopwatch, !c.Until.IsZero()} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("--countdown, --stopwatch and --until are mutually exclusive")
+	}
 
 	return nil
 }
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// parseClockTime parses a wall-clock time such as "17:30" as its next
+// occurrence after now.
+func parseClockTime(s string, now time.Time) (time.Time, error) {
+	var t time.Time
+	var err error
+	for _, layout := range []string{"15:04", "15:04:05", "3:04pm", "3pm"} {
+		if t, err = time.ParseInLocation(layout, s, now.Location()); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a time like 17:30, got %q", s)
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/timer"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandSequence(t *testing.T) {
+	data := `
+sequence:
+  - label: Warm-up
+    mode: work
+    duration: 10m
+  - repeat: 3
+    steps:
+      - mode: work
+        duration: 50m
+      - mode: short_break
+        duration: 10m
+  - label: Lunch
+    mode: long_break
+    duration: 60m
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := expandSequence(cfg.Sequence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 8 {
+		t.Fatalf("expected 8 steps, got %d", len(steps))
+	}
+	if steps[0] != (timer.Step{Mode: timer.ModeWork, Duration: 10 * time.Minute, Label: "Warm-up"}) {
+		t.Errorf("unexpected first step %+v", steps[0])
+	}
+	if steps[6].Mode != timer.ModeShortBreak || steps[6].Duration != 10*time.Minute {
+		t.Errorf("unexpected step 7 %+v", steps[6])
+	}
+	if steps[7].Label != "Lunch" || steps[7].Mode != timer.ModeLongBreak {
+		t.Errorf("unexpected last step %+v", steps[7])
+	}
+}
+
+func TestExpandSequenceErrors(t *testing.T) {
+	cases := map[string][]SequenceStep{
+		"bad mode":      {{Mode: "nap", Duration: "10m"}},
+		"bad duration":  {{Mode: "work", Duration: "soon"}},
+		"zero duration": {{Mode: "work", Duration: "0s"}},
+		"bad repeat":    {{Mode: "work", Duration: "10m", Repeat: -1}},
+		"nested":        {{Repeat: 2, Steps: []SequenceStep{{Mode: "work"}}}},
+	}
+	for name, seq := range cases {
+		if _, err := expandSequence(seq); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseClockTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"17:30":  time.Date(2024, 1, 1, 17, 30, 0, 0, time.UTC),
+		"5:30pm": time.Date(2024, 1, 1, 17, 30, 0, 0, time.UTC),
+		"09:00":  time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		"16:00":  time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := parseClockTime(in, now)
+		if err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s: expected %v, got %v", in, want, got)
+		}
+	}
+	if _, err := parseClockTime("teatime", now); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	tests := map[string]string{
+		"~/todo.txt":    "/home/u/todo.txt",
+		"~":             "/home/u",
+		"/abs/todo.txt": "/abs/todo.txt",
+		"~other/x":      "~other/x",
+		"":              "",
+	}
+	for in, want := range tests {
+		if got, err := expandHome(in); err != nil || got != want {
+			t.Errorf("expandHome(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestXDGDirs(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_DATA_HOME", "relative/is/ignored")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	tests := []struct {
+		name string
+		dir  func() (string, error)
+		want string
+	}{
+		{"config", configDir, "/xdg/config/tui-timer"},
+		{"data", DataDir, "/home/u/.local/share/tui-timer"},
+		{"state", StateDir, "/home/u/.local/state/tui-timer"},
+	}
+	for _, tt := range tests {
+		if got, err := tt.dir(); err != nil || got != tt.want {
+			t.Errorf("%s dir = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	opts, err := DefaultConfig().Log.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Path != "/home/u/.local/share/tui-timer/log.txt" || opts.MaxSize != 1<<20 {
+		t.Errorf("unexpected log options %+v", opts)
+	}
+}
+
+func TestLogFlags(t *testing.T) {
+	c := DefaultConfig()
+	if err := c.ApplyCLIFlags([]string{"--log-file", "/tmp/t.log", "--log-level", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Log.File != "/tmp/t.log" || c.Log.Level != "debug" {
+		t.Errorf("unexpected log config %+v", c.Log)
+	}
+	if err := DefaultConfig().ApplyCLIFlags([]string{"--log-level", "loud"}); err == nil {
+		t.Error("expected an invalid level to fail")
+	}
+}
+
+func TestAmbient(t *testing.T) {
+	c := DefaultConfig()
+	if err := c.Ambient.validate(); err != nil {
+		t.Errorf("expected the defaults to be valid, got %v", err)
+	}
+	if err := c.ApplyCLIFlags([]string{"--ambient", "pink"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Ambient.Enabled || c.Ambient.Noise != "pink" {
+		t.Errorf("unexpected ambient config %+v", c.Ambient)
+	}
+	if err := c.ApplyCLIFlags([]string{"--ambient", "off"}); err != nil || c.Ambient.Enabled {
+		t.Errorf("expected --ambient off to disable it, got %+v/%v", c.Ambient, err)
+	}
+	if err := DefaultConfig().ApplyCLIFlags([]string{"--ambient", "grey"}); err == nil {
+		t.Error("expected an unknown noise to fail")
+	}
+
+	for _, bad := range []AmbientConfig{
+		{Noise: "grey", Volume: 0.3},
+		{Noise: "white", Volume: 2},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+	if _, _, err := (AmbientConfig{File: filepath.Join(t.TempDir(), "rain.wav")}).Source(); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestVoiceTemplates(t *testing.T) {
+	if DefaultConfig().Voice.Templates.WorkDone == nil {
+		t.Error("expected the default messages to be parsed")
+	}
+	_, err := VoiceMessages{Start: "Go", WorkDone: "Cycle {{.Cylce}} done"}.parse()
+	if err == nil || !strings.Contains(err.Error(), "voice.messages.work_done") || !strings.Contains(err.Error(), "Cylce") {
+		t.Errorf("expected the message and field to be named, got %v", err)
+	}
+}
+
+func TestSoundsChime(t *testing.T) {
+	if c, err := (SoundsConfig{}).chime(); err != nil || len(c) != len(sound.DefaultChime) {
+		t.Errorf("expected the default chime, got %v/%v", c, err)
+	}
+
+	c, err := SoundsConfig{Beep: []ToneConfig{
+		{Frequency: 660, Duration: "200ms", Attack: "10ms", Release: "100ms", Volume: 0.8},
+		{Duration: "50ms"},
+	}}.chime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sound.Chime{
+		{Freq: 660, Duration: 200 * time.Millisecond, Attack: 10 * time.Millisecond, Release: 100 * time.Millisecond, Volume: 0.8},
+		{Duration: 50 * time.Millisecond, Volume: 0.5},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+
+	for _, bad := range []ToneConfig{
+		{Frequency: 440},
+		{Frequency: 440, Duration: "soon"},
+		{Frequency: 440, Duration: "1s", Volume: 2},
+	} {
+		if _, err := (SoundsConfig{Beep: []ToneConfig{bad}}).chime(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestSoundPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pack := filepath.Join(home, "data", "tui-timer", "sounds", "bells")
+	write(filepath.Join(pack, "pack.yaml"), "sounds:\n  work_done: gong.wav\n  break_done: ding.wav\n")
+	write(filepath.Join(pack, "gong.wav"), "")
+	write(filepath.Join(pack, "ding.wav"), "")
+	write(filepath.Join(home, "config", "tui-timer", "mine.wav"), "")
+
+	paths, err := SoundsConfig{Pack: "bells", Files: map[string]string{"break_done": "mine.wav"}}.paths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"work_done":  filepath.Join(pack, "gong.wav"),
+		"break_done": filepath.Join(home, "config", "tui-timer", "mine.wav"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected %v, got %v", want, paths)
+	}
+
+	if _, err := (SoundsConfig{Pack: "gongs"}).paths(); err == nil || !strings.Contains(err.Error(), "installed: bells") {
+		t.Errorf("expected a missing pack to list the installed ones, got %v", err)
+	}
+	if _, err := (SoundsConfig{Files: map[string]string{"lunch": "mine.wav"}}).paths(); err == nil {
+		t.Error("expected an unknown event to be rejected")
+	}
+	if _, err := (SoundsConfig{Files: map[string]string{"tick": "missing.wav"}}).paths(); err == nil {
+		t.Error("expected a missing file to be rejected")
+	}
+}
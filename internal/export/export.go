@@ -0,0 +1,209 @@
+// Package export converts session history for other tools: CSV for
+// spreadsheets and time trackers, iCalendar events for calendars, Org-mode
+// clock entries for Emacs and plain JSON.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/history"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{"csv", "ics", "org", "json"}
+
+// Write writes records to w in format.
+func Write(w io.Writer, format string, records []history.Record) error {
+	switch format {
+	case "csv":
+		return CSV(w, records)
+	case "ics":
+		return ICS(w, records)
+	case "org":
+		return Org(w, records)
+	case "json":
+		return JSON(w, records)
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// CSV writes one row per session, with a header row. Times are RFC 3339
+// and lengths in minutes.
+func CSV(w io.Writer, records []history.Record) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "start", "end", "mode", "task", "label", "planned_minutes",
+		"actual_minutes", "outcome", "interruptions", "reason"})
+	for _, r := range records {
+		cw.Write([]string{
+			r.ID,
+			r.Start.Format(time.RFC3339),
+			r.End.Format(time.RFC3339),
+			r.Mode,
+			r.Task,
+			r.Label,
+			minutes(r.Planned),
+			minutes(r.Actual),
+			string(r.Outcome),
+			strconv.Itoa(len(r.Interruptions)),
+			r.Reason,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func minutes(d time.Duration) string {
+	return strconv.FormatFloat(d.Minutes(), 'f', -1, 64)
+}
+
+// ICS writes an iCalendar file with one VEVENT per session.
+func ICS(w io.Writer, records []history.Record) error {
+	ew := &errWriter{w: w}
+	line := func(s string) { ew.WriteString(fold(s) + "\r\n") }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//tui-timer//Session history//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, r := range records {
+		line("BEGIN:VEVENT")
+		line("UID:" + r.ID + "@tui-timer")
+		line("DTSTAMP:" + icsTime(r.End))
+		line("DTSTART:" + icsTime(r.Start))
+		line("DTEND:" + icsTime(r.End))
+		line("SUMMARY:" + icsText(summary(r)))
+		line("DESCRIPTION:" + icsText(describe(r)))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return ew.err
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes a TEXT value (RFC 5545, 3.3.11).
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// fold splits a content line into lines of at most 75 octets, continued
+// with a leading space (RFC 5545, 3.1), without splitting UTF-8 sequences.
+func fold(s string) string {
+	const limit = 75
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
+
+func summary(r history.Record) string {
+	s := "Focus"
+	if !r.IsWork() {
+		s = "Break"
+		if r.Label != "" {
+			s = r.Label
+		}
+	}
+	if r.Task != "" {
+		s += ": " + r.Task
+	}
+	if r.Outcome == history.OutcomeVoided {
+		s += " (voided)"
+	}
+	return s
+}
+
+func describe(r history.Record) string {
+	d := fmt.Sprintf("%s, %s of %s", strings.ReplaceAll(string(r.Outcome), "_", " "),
+		r.Actual.Round(time.Second), r.Planned.Round(time.Second))
+	switch n := len(r.Interruptions); n {
+	case 0:
+	case 1:
+		d += ", 1 interruption"
+	default:
+		d += fmt.Sprintf(", %d interruptions", n)
+	}
+	if r.Reason != "" {
+		d += "\n" + r.Reason
+	}
+	return d
+}
+
+// Org writes an Org-mode heading per task, in order of first appearance,
+// with a CLOCK line per work session in its LOGBOOK. Breaks are left out so
+// that clock tables only sum focus time, and each session is clocked from
+// its start for as long as it actually ran, so pauses are not counted.
+func Org(w io.Writer, records []history.Record) error {
+	var order []string
+	byTask := map[string][]history.Record{}
+	for _, r := range records {
+		if !r.IsWork() {
+			continue
+		}
+		title := r.Task
+		if title == "" {
+			title = "Focus"
+		}
+		if _, ok := byTask[title]; !ok {
+			order = append(order, title)
+		}
+		byTask[title] = append(byTask[title], r)
+	}
+
+	ew := &errWriter{w: w}
+	for _, title := range order {
+		ew.WriteString("* " + title + "\n  :LOGBOOK:\n")
+		for _, r := range byTask[title] {
+			end := r.Start.Add(r.Actual)
+			d := end.Truncate(time.Minute).Sub(r.Start.Truncate(time.Minute))
+			ew.WriteString(fmt.Sprintf("  CLOCK: %s--%s => %2d:%02d\n",
+				orgTime(r.Start), orgTime(end), int(d.Hours()), int(d.Minutes())%60))
+		}
+		ew.WriteString("  :END:\n")
+	}
+	return ew.err
+}
+
+func orgTime(t time.Time) string {
+	return t.Format("[2006-01-02 Mon 15:04]")
+}
+
+// JSON writes the records as one indented JSON array.
+func JSON(w io.Writer, records []history.Record) error {
+	if records == nil {
+		records = []history.Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// errWriter remembers the first write error so that a format can be
+// written without checking every line.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) WriteString(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
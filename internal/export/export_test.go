@@ -0,0 +1,141 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+func testRecords() []history.Record {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	return []history.Record{
+		{
+			ID: "a1", Mode: "work", Task: "Report, draft; v2", Planned: 25 * time.Minute, Actual: 25 * time.Minute,
+			Start: start, End: start.Add(25 * time.Minute), Outcome: history.OutcomeCompleted, Cycle: 1,
+			Interruptions: []timer.Interruption{{Kind: timer.InterruptExternal, At: start}},
+		},
+		{
+			ID: "b2", Mode: "work", Planned: 25 * time.Minute, Actual: 90 * time.Second,
+			Start: start.Add(time.Hour), End: start.Add(time.Hour + 2*time.Minute),
+			Outcome: history.OutcomeVoided, Reason: "fire drill",
+		},
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "csv", testRecords()); err != nil {
+		t.Fatal(err)
+	}
+	want := `id,start,end,mode,task,label,planned_minutes,actual_minutes,outcome,interruptions,reason
+a1,2024-01-01T09:00:00Z,2024-01-01T09:25:00Z,work,"Report, draft; v2",,25,25,completed,1,
+b2,2024-01-01T10:00:00Z,2024-01-01T10:02:00Z,work,,,25,1.5,voided,0,fire drill
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestICS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "ics", testRecords()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:a1@tui-timer\r\n",
+		"DTSTART:20240101T090000Z\r\nDTEND:20240101T092500Z\r\n",
+		`SUMMARY:Focus: Report\, draft\; v2` + "\r\n",
+		"SUMMARY:Focus (voided)\r\n",
+		`DESCRIPTION:completed\, 25m0s of 25m0s\, 1 interruption` + "\r\n",
+		`DESCRIPTION:voided\, 1m30s of 25m0s\nfire drill` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected 2 events")
+	}
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 80)
+	folded := fold(line)
+	for _, l := range strings.Split(folded, "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line of %d octets: %q", len(l), l)
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Error("unfolding should restore the line")
+	}
+}
+
+func TestOrg(t *testing.T) {
+	start := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+	records := append(testRecords(),
+		history.Record{
+			ID: "c3", Mode: "short_break", Planned: 5 * time.Minute, Actual: 5 * time.Minute,
+			Start: start, End: start.Add(5 * time.Minute), Outcome: history.OutcomeCompleted,
+		},
+		// Paused for two hours halfway through.
+		history.Record{
+			ID: "d4", Mode: "work", Task: "Report, draft; v2", Planned: 25 * time.Minute, Actual: 25 * time.Minute,
+			Start: start.Add(time.Hour), End: start.Add(3*time.Hour + 25*time.Minute), Outcome: history.OutcomeCompleted,
+		},
+	)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "org", records); err != nil {
+		t.Fatal(err)
+	}
+	want := `* Report, draft; v2
+  :LOGBOOK:
+  CLOCK: [2024-01-01 Mon 09:00]--[2024-01-01 Mon 09:25] =>  0:25
+  CLOCK: [2024-01-01 Mon 14:00]--[2024-01-01 Mon 14:25] =>  0:25
+  :END:
+* Focus
+  :LOGBOOK:
+  CLOCK: [2024-01-01 Mon 10:00]--[2024-01-01 Mon 10:01] =>  0:01
+  :END:
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected Org:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "json", nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected an empty array, got %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, "json", testRecords()); err != nil {
+		t.Fatal(err)
+	}
+	var got []history.Record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Reason != "fire drill" || got[0].Actual != 25*time.Minute {
+		t.Errorf("unexpected round trip %+v", got)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "xlsx", nil); err == nil {
+		t.Error("expected an error")
+	}
+}
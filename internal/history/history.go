@@ -0,0 +1,315 @@
+// Package history stores completed, voided and skipped sessions as JSON
+// Lines and reads them back for reporting.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+const historyFile = "sessions.jsonl"
+
+// Outcome says how a session ended.
+type Outcome string
+
+const (
+	OutcomeCompleted      Outcome = "completed"
+	OutcomeCompletedEarly Outcome = "completed_early"
+	OutcomeVoided         Outcome = "voided"
+	OutcomeSkipped        Outcome = "skipped"
+)
+
+// Counts reports whether the outcome counts as a finished session.
+func (o Outcome) Counts() bool {
+	return o == OutcomeCompleted || o == OutcomeCompletedEarly
+}
+
+// Record is one session in the history.
+type Record struct {
+	ID            string
+	Mode          string // "work", "short_break" or "long_break"
+	Label         string
+	Planned       time.Duration // zero for sessions that counted up
+	Actual        time.Duration
+	Start         time.Time
+	End           time.Time
+	Outcome       Outcome
+	Reason        string // why a session was voided
+	Cycle         int    // completed work cycles after the session
+	Task          string
+	Interruptions []timer.Interruption
+}
+
+// IsWork reports whether the record is a work session.
+func (r Record) IsWork() bool {
+	return r.Mode == "work"
+}
+
+// recordJSON is the on-disk form of Record, with readable durations.
+type recordJSON struct {
+	ID            string               `json:"id"`
+	Mode          string               `json:"mode"`
+	Label         string               `json:"label,omitempty"`
+	Planned       string               `json:"planned"`
+	Actual        string               `json:"actual"`
+	Start         time.Time            `json:"start"`
+	End           time.Time            `json:"end"`
+	Outcome       Outcome              `json:"outcome"`
+	Reason        string               `json:"reason,omitempty"`
+	Cycle         int                  `json:"cycle"`
+	Task          string               `json:"task,omitempty"`
+	Interruptions []timer.Interruption `json:"interruptions,omitempty"`
+}
+
+func (r Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recordJSON{
+		ID:            r.ID,
+		Mode:          r.Mode,
+		Label:         r.Label,
+		Planned:       r.Planned.String(),
+		Actual:        r.Actual.String(),
+		Start:         r.Start,
+		End:           r.End,
+		Outcome:       r.Outcome,
+		Reason:        r.Reason,
+		Cycle:         r.Cycle,
+		Task:          r.Task,
+		Interruptions: r.Interruptions,
+	})
+}
+
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var j recordJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	planned, err := time.ParseDuration(j.Planned)
+	if err != nil {
+		return fmt.Errorf("planned: %w", err)
+	}
+	actual, err := time.ParseDuration(j.Actual)
+	if err != nil {
+		return fmt.Errorf("actual: %w", err)
+	}
+	*r = Record{
+		ID:            j.ID,
+		Mode:          j.Mode,
+		Label:         j.Label,
+		Planned:       planned,
+		Actual:        actual,
+		Start:         j.Start,
+		End:           j.End,
+		Outcome:       j.Outcome,
+		Reason:        j.Reason,
+		Cycle:         j.Cycle,
+		Task:          j.Task,
+		Interruptions: j.Interruptions,
+	}
+	return nil
+}
+
+// DefaultPath returns sessions.jsonl in the XDG data dir.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFile), nil
+}
+
+// Store appends records to a JSON Lines file.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore returns a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file the store writes to.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append writes r as one line. A record without an ID gets a random one.
+// If a previous write was cut short, the partial line is terminated first
+// so that it cannot corrupt r.
+func (s *Store) Append(r Record) error {
+	if r.ID == "" {
+		r.ID = newID()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if terminated, err := endsWithNewline(f); err != nil {
+		return err
+	} else if !terminated {
+		line = append([]byte("\n"), line...)
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load reads every record in the store. See Read.
+func (s *Store) Load() ([]Record, int, error) {
+	return Load(s.path)
+}
+
+// Load reads the records in the file at path. A missing file has no
+// records. See Read for how bad lines are handled.
+func Load(path string) ([]Record, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses JSON Lines from r. Blank lines are ignored and lines that do
+// not parse, such as a line cut short by a crash, are skipped and counted
+// in bad.
+func Read(r io.Reader) (records []Record, bad int, err error) {
+	br := bufio.NewReader(r)
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var rec Record
+			if err := json.Unmarshal(line, &rec); err != nil {
+				bad++
+			} else {
+				records = append(records, rec)
+			}
+		}
+		if readErr == io.EOF {
+			return records, bad, nil
+		}
+		if readErr != nil {
+			return records, bad, readErr
+		}
+	}
+}
+
+// Handler returns an engine event handler that appends a record for every
+// session that ends. Write errors are passed to onError, if set.
+func (s *Store) Handler(onError func(error)) timer.Handler {
+	return func(evt timer.Event) {
+		rec, ok := FromEvent(evt)
+		if !ok {
+			return
+		}
+		if err := s.Append(rec); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// FromEvent builds the record for an event that ends a session. ok is
+// false for other events and for standalone countdowns and stopwatches.
+func FromEvent(evt timer.Event) (rec Record, ok bool) {
+	if evt.Kind == timer.KindCountdown || evt.Kind == timer.KindStopwatch {
+		return rec, false
+	}
+
+	var outcome Outcome
+	switch evt.Type {
+	case timer.EventWorkDone, timer.EventBreakDone, timer.EventFinished:
+		outcome = OutcomeCompleted
+	case timer.EventCompletedEarly:
+		outcome = OutcomeCompletedEarly
+	case timer.EventVoided:
+		outcome = OutcomeVoided
+	case timer.EventSkipped:
+		outcome = OutcomeSkipped
+	default:
+		return rec, false
+	}
+
+	start := evt.Start
+	if start.IsZero() {
+		start = evt.At
+	}
+	rec = Record{
+		ID:            newID(),
+		Mode:          modeName(evt.Mode),
+		Label:         evt.Label,
+		Planned:       evt.Planned,
+		Actual:        evt.Elapsed,
+		Start:         start,
+		End:           evt.At,
+		Outcome:       outcome,
+		Reason:        evt.Reason,
+		Cycle:         evt.Cycle,
+		Interruptions: evt.Interruptions,
+	}
+	if evt.Mode == timer.ModeWork {
+		rec.Task = evt.Task
+	}
+	return rec, true
+}
+
+func modeName(m timer.Mode) string {
+	switch m {
+	case timer.ModeShortBreak:
+		return "short_break"
+	case timer.ModeLongBreak:
+		return "long_break"
+	default:
+		return "work"
+	}
+}
+
+func endsWithNewline(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() == 0 {
+		return true, nil
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] == '\n', nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,148 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+func testRecord(start time.Time) Record {
+	return Record{
+		ID:      "abc",
+		Mode:    "work",
+		Planned: 25 * time.Minute,
+		Actual:  25 * time.Minute,
+		Start:   start,
+		End:     start.Add(25 * time.Minute),
+		Outcome: OutcomeCompleted,
+		Cycle:   1,
+		Interruptions: []timer.Interruption{
+			{Kind: timer.InterruptInternal, Note: "email", At: start.Add(5 * time.Minute)},
+		},
+	}
+}
+
+func TestAppendLoad(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "data", "sessions.jsonl"))
+
+	if recs, bad, err := s.Load(); err != nil || bad != 0 || len(recs) != 0 {
+		t.Fatalf("expected empty history, got %d/%d/%v", len(recs), bad, err)
+	}
+
+	want := testRecord(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	if err := s.Append(want); err != nil {
+		t.Fatal(err)
+	}
+	second := want
+	second.ID = ""
+	second.Outcome = OutcomeVoided
+	second.Reason = "meeting"
+	if err := s.Append(second); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, bad, err := s.Load()
+	if err != nil || bad != 0 {
+		t.Fatalf("load: bad=%d err=%v", bad, err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	if !reflect.DeepEqual(recs[0], want) {
+		t.Errorf("expected %+v, got %+v", want, recs[0])
+	}
+	if recs[1].ID == "" || recs[1].Reason != "meeting" {
+		t.Errorf("unexpected second record %+v", recs[1])
+	}
+
+	data, _ := os.ReadFile(s.Path())
+	if !strings.Contains(string(data), `"planned":"25m0s"`) {
+		t.Errorf("expected readable durations on disk, got %s", data)
+	}
+}
+
+func TestReadSkipsCorruptLines(t *testing.T) {
+	input := `{"id":"a","mode":"work","planned":"25m0s","actual":"25m0s","outcome":"completed"}
+
+not json
+{"id":"b","mode":"work","planned":"soon","actual":"1m0s"}
+{"id":"c","mode":"short_break","planned":"5m0s","actual":"5m0s","outcome":"completed"}
+{"id":"d","mode":"wo`
+	recs, bad, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].ID != "a" || recs[1].ID != "c" {
+		t.Errorf("unexpected records %+v", recs)
+	}
+	if bad != 3 {
+		t.Errorf("expected 3 bad lines, got %d", bad)
+	}
+}
+
+func TestAppendAfterPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	if err := os.WriteFile(path, []byte(`{"id":"cut","mo`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(path)
+	if err := s.Append(testRecord(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, bad, err := s.Load()
+	if err != nil || len(recs) != 1 || bad != 1 {
+		t.Errorf("expected 1 record and 1 bad line, got %d/%d/%v", len(recs), bad, err)
+	}
+}
+
+func TestFromEvent(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	evt := timer.Event{
+		Type:    timer.EventCompletedEarly,
+		Mode:    timer.ModeWork,
+		Label:   "Work",
+		Cycle:   3,
+		Planned: 25 * time.Minute,
+		Elapsed: 20 * time.Minute,
+		Task:    "Invoicing",
+		Start:   start,
+		At:      start.Add(22 * time.Minute),
+	}
+
+	rec, ok := FromEvent(evt)
+	if !ok {
+		t.Fatal("expected a record")
+	}
+	if rec.Outcome != OutcomeCompletedEarly || rec.Actual != 20*time.Minute || rec.Cycle != 3 {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if !rec.Start.Equal(start) || !rec.End.Equal(evt.At) || rec.ID == "" {
+		t.Errorf("unexpected timestamps or id %+v", rec)
+	}
+	if rec.Task != "Invoicing" {
+		t.Errorf("expected the task to be recorded, got %q", rec.Task)
+	}
+
+	brk := evt
+	brk.Type, brk.Mode = timer.EventBreakDone, timer.ModeShortBreak
+	if rec, _ := FromEvent(brk); rec.Task != "" {
+		t.Errorf("expected breaks not to be billed to the task, got %q", rec.Task)
+	}
+
+	for _, e := range []timer.Event{
+		{Type: timer.EventTick},
+		{Type: timer.EventPaused},
+		{Type: timer.EventFinished, Kind: timer.KindCountdown},
+	} {
+		if _, ok := FromEvent(e); ok {
+			t.Errorf("expected no record for %v", e.Type)
+		}
+	}
+}
@@ -1,53 +1,234 @@
+// Package logger writes the application log, rotating it by size and age.
 package logger
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
+const timeFormat = "2006-01-02 15:04:05"
+
+// Level is the severity of a log line.
+type Level int
+
 const (
-	appName = "tui-timer-timer"
-	logFile = "log.txt"
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error".
+func ParseLevel(s string) (Level, error) {
+	for l := LevelDebug; l <= LevelError; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+}
+
+// Options configure a Logger. Zero limits disable the matching rotation or
+// retention rule.
+type Options struct {
+	Path  string
+	Level Level
+
+	// MaxSize rotates the log before it grows past this many bytes.
+	MaxSize int64
+	// MaxAge rotates the log once its first line is older than this, and
+	// deletes rotated logs whose last line is older than this.
+	MaxAge time.Duration
+	// MaxBackups is how many rotated logs (log.txt.1, log.txt.2, ...) to
+	// keep.
+	MaxBackups int
+}
+
 type Logger struct {
-	file *os.File
+	mu      sync.Mutex
+	opts    Options
+	file    *os.File
+	size    int64
+	started time.Time // time of the first line in the current file
+	now     func() time.Time
 }
 
-func New() (*Logger, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
+// New opens the log at opts.Path. A log that is already too big or too old
+// is rotated on the next write.
+func New(opts Options) (*Logger, error) {
+	l := &Logger{opts: opts, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
 		return nil, err
 	}
-
-	dir := filepath.Join(home, ".local", "share", appName)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if err := l.open(); err != nil {
 		return nil, err
 	}
+	l.prune()
+	return l, nil
+}
 
-	path := filepath.Join(dir, logFile)
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.opts.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return &Logger{file: f}, nil
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file = f
+	l.size = fi.Size()
+	l.started = firstTimestamp(l.opts.Path)
+	return nil
 }
 
+// Log writes an info line.
 func (l *Logger) Log(format string, args ...any) {
+	l.write(LevelInfo, format, args...)
+}
+
+// Debug writes a line only shown at the debug level.
+func (l *Logger) Debug(format string, args ...any) {
+	l.write(LevelDebug, format, args...)
+}
+
+// Warn writes a warning line.
+func (l *Logger) Warn(format string, args ...any) {
+	l.write(LevelWarn, format, args...)
+}
+
+// Error writes an error line.
+func (l *Logger) Error(format string, args ...any) {
+	l.write(LevelError, format, args...)
+}
+
+func (l *Logger) write(level Level, format string, args ...any) {
+	if l == nil || level < l.opts.Level {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.file == nil {
 		return
 	}
-	ts := time.Now().Format("2006-01-02 15:04:05")
-	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintf(l.file, "[%s] %s\n", ts, msg)
+
+	now := l.now()
+	line := fmt.Sprintf("[%s] %s\n", now.Format(timeFormat), fmt.Sprintf(format, args...))
+	if level != LevelInfo {
+		line = fmt.Sprintf("[%s] %s: %s\n", now.Format(timeFormat), strings.ToUpper(level.String()), fmt.Sprintf(format, args...))
+	}
+
+	if l.size > 0 && l.due(now, int64(len(line))) {
+		if err := l.rotate(); err != nil || l.file == nil {
+			return
+		}
+	}
+	n, _ := l.file.WriteString(line)
+	l.size += int64(n)
+	if l.started.IsZero() {
+		l.started = now
+	}
+}
+
+// due reports whether the log must be rotated before writing n bytes.
+func (l *Logger) due(now time.Time, n int64) bool {
+	if l.opts.MaxSize > 0 && l.size+n > l.opts.MaxSize {
+		return true
+	}
+	return l.opts.MaxAge > 0 && !l.started.IsZero() && now.Sub(l.started) > l.opts.MaxAge
+}
+
+// rotate shifts log.txt to log.txt.1, log.txt.1 to log.txt.2 and so on,
+// dropping what is past MaxBackups, and starts a new log.
+func (l *Logger) rotate() error {
+	l.file.Close()
+	l.file = nil
+
+	path := l.opts.Path
+	if l.opts.MaxBackups <= 0 {
+		os.Remove(path)
+	} else {
+		os.Remove(backup(path, l.opts.MaxBackups))
+		for i := l.opts.MaxBackups - 1; i >= 1; i-- {
+			os.Rename(backup(path, i), backup(path, i+1))
+		}
+		os.Rename(path, backup(path, 1))
+	}
+	l.prune()
+	return l.open()
+}
+
+// prune deletes rotated logs beyond MaxBackups or older than MaxAge.
+func (l *Logger) prune() {
+	matches, _ := filepath.Glob(l.opts.Path + ".*")
+	for _, m := range matches {
+		var i int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(m, l.opts.Path+"."), "%d", &i); err != nil || m != backup(l.opts.Path, i) {
+			continue
+		}
+		fi, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		if i > l.opts.MaxBackups || (l.opts.MaxAge > 0 && l.now().Sub(fi.ModTime()) > l.opts.MaxAge) {
+			os.Remove(m)
+		}
+	}
+}
+
+func backup(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// firstTimestamp reads the time of the first line of the log at path.
+func firstTimestamp(path string) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString(']')
+	if err != nil || !strings.HasPrefix(line, "[") {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(timeFormat, strings.Trim(line, "[]"), time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.file != nil {
-		return l.file.Close()
+		err := l.file.Close()
+		l.file = nil
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger(t *testing.T, opts Options) (*Logger, *time.Time) {
+	t.Helper()
+	if opts.Path == "" {
+		opts.Path = filepath.Join(t.TempDir(), "logs", "log.txt")
+	}
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local)
+	l, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return now }
+	t.Cleanup(func() { l.Close() })
+	return l, &now
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLevels(t *testing.T) {
+	l, _ := newTestLogger(t, Options{Level: LevelInfo})
+	l.Debug("hidden")
+	l.Log("started %s", "work")
+	l.Warn("careful")
+	l.Error("broken: %v", os.ErrNotExist)
+
+	want := "[2024-01-01 09:00:00] started work\n" +
+		"[2024-01-01 09:00:00] WARN: careful\n" +
+		"[2024-01-01 09:00:00] ERROR: broken: file does not exist\n"
+	if got := read(t, l.opts.Path); got != want {
+		t.Errorf("unexpected log:\n%s", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, err := ParseLevel("DEBUG"); err != nil || l != LevelDebug {
+		t.Errorf("expected debug, got %v/%v", l, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	l, _ := newTestLogger(t, Options{MaxSize: 60, MaxBackups: 2})
+	path := l.opts.Path
+
+	for i := 1; i <= 7; i++ {
+		l.Log("line %d", i) // 30 bytes each: two lines per file
+	}
+
+	if got := read(t, path); got != "[2024-01-01 09:00:00] line 7\n" {
+		t.Errorf("unexpected current log %q", got)
+	}
+	if got := read(t, path+".1"); !strings.Contains(got, "line 5") || !strings.Contains(got, "line 6") {
+		t.Errorf("unexpected first backup %q", got)
+	}
+	if got := read(t, path+".2"); !strings.Contains(got, "line 3") {
+		t.Errorf("unexpected second backup %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only 2 backups")
+	}
+}
+
+func TestRotateByAge(t *testing.T) {
+	l, now := newTestLogger(t, Options{MaxAge: 24 * time.Hour, MaxBackups: 5})
+	path := l.opts.Path
+
+	l.Log("monday")
+	*now = now.Add(23 * time.Hour)
+	l.Log("still monday's file")
+	*now = now.Add(2 * time.Hour)
+	l.Log("tuesday")
+
+	if got := read(t, path); !strings.Contains(got, "tuesday") || strings.Contains(got, "monday") {
+		t.Errorf("expected a fresh log, got %q", got)
+	}
+	if got := read(t, path+".1"); !strings.Contains(got, "still monday's file") {
+		t.Errorf("unexpected backup %q", got)
+	}
+}
+
+func TestReopenKeepsAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	old := "[2023-12-01 09:00:00] old line\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A stale rotated log past the retention age.
+	if err := os.WriteFile(path+".1", []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-60 * 24 * time.Hour)
+	os.Chtimes(path+".1", stale, stale)
+
+	l, err := New(Options{Path: path, MaxAge: 30 * 24 * time.Hour, MaxBackups: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("expected the stale backup to be deleted")
+	}
+
+	l.Log("new line")
+	if got := read(t, path); strings.Contains(got, "old line") {
+		t.Errorf("expected the month-old log to rotate, got %q", got)
+	}
+	if got := read(t, path+".1"); got != old {
+		t.Errorf("expected the old log as backup, got %q", got)
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+	l.Log("nothing")
+	l.Error("nothing")
+	if err := l.Close(); err != nil {
+		t.Error(err)
+	}
+}
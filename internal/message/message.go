@@ -0,0 +1,115 @@
+// Package message renders voice messages, which are text/template
+// templates executed with the session an event is about.
+package message
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data is what a message template is executed with.
+type Data struct {
+	Mode      string   // "work", "short break" or "long break"
+	Label     string   // session label, e.g. a sequence step's name
+	Cycle     int      // completed work cycles
+	Task      string   // what the work sessions are spent on
+	Planned   Duration // planned session length, zero when counting up
+	Elapsed   Duration // time run in the session
+	Next      Session  // the session that follows, for work and break done
+	UntilLong int      // work sessions left before the long break, 0 without one
+	Today     Duration // focus time today, including a session that just ended
+	Pomodoros int      // work sessions completed today
+}
+
+// Session describes the session that follows.
+type Session struct {
+	Mode     string
+	Label    string
+	Duration Duration
+}
+
+// Duration is a time.Duration that prints in words, like "1 hour 5
+// minutes", so that it can be spoken.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	d = Duration(time.Duration(d).Round(time.Second))
+	h := int(time.Duration(d).Hours())
+	m := int(time.Duration(d).Minutes()) % 60
+	s := int(time.Duration(d).Seconds()) % 60
+
+	var parts []string
+	if h > 0 {
+		parts = append(parts, plural(h, "hour"))
+	}
+	if m > 0 {
+		parts = append(parts, plural(m, "minute"))
+	}
+	if s > 0 && h == 0 {
+		parts = append(parts, plural(s, "second"))
+	}
+	if len(parts) == 0 {
+		return "0 minutes"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Minutes returns the duration as a whole number of minutes.
+func (d Duration) Minutes() int {
+	return int(time.Duration(d).Round(time.Minute).Minutes())
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// sample exercises every field when a template is checked.
+var sample = Data{
+	Mode:      "work",
+	Label:     "Work",
+	Cycle:     1,
+	Task:      "Task",
+	Planned:   Duration(25 * time.Minute),
+	Elapsed:   Duration(25 * time.Minute),
+	Next:      Session{Mode: "short break", Label: "Short Break", Duration: Duration(5 * time.Minute)},
+	UntilLong: 3,
+	Today:     Duration(25 * time.Minute),
+	Pomodoros: 1,
+}
+
+// Template is a parsed message.
+type Template struct {
+	t *template.Template
+}
+
+// Parse parses text as a message template named name and executes it once
+// with sample data, so that misspelt fields are reported up front rather
+// than when the message is due.
+func Parse(name, text string) (*Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(io.Discard, sample); err != nil {
+		return nil, err
+	}
+	return &Template{t: t}, nil
+}
+
+// Render executes the template with d. A nil template renders nothing.
+func (t *Template) Render(d Data) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := t.t.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
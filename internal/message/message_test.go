@@ -0,0 +1,67 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0 minutes"},
+		{30 * time.Second, "30 seconds"},
+		{time.Minute, "1 minute"},
+		{5 * time.Minute, "5 minutes"},
+		{90 * time.Second, "1 minute 30 seconds"},
+		{time.Hour + 5*time.Minute + 10*time.Second, "1 hour 5 minutes"},
+		{2 * time.Hour, "2 hours"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.d).String(); got != tt.want {
+			t.Errorf("Duration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+	if m := Duration(4*time.Minute + 40*time.Second).Minutes(); m != 5 {
+		t.Errorf("expected 5 minutes, got %d", m)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl, err := Parse("work_done", `Cycle {{.Cycle}} done, {{.Next.Duration}} {{.Next.Mode}}.
+		{{if .Task}}Task: {{.Task}}.{{end}} {{.UntilLong}} until the long break, {{.Today}} today.`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tmpl.Render(Data{
+		Cycle:     3,
+		Next:      Session{Mode: "short break", Duration: Duration(5 * time.Minute)},
+		UntilLong: 1,
+		Today:     Duration(75 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Cycle 3 done, 5 minutes short break. 1 until the long break, 1 hour 15 minutes today."
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if got, err := (*Template)(nil).Render(Data{}); got != "" || err != nil {
+		t.Errorf("expected a nil template to render nothing, got %q/%v", got, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for text, want := range map[string]string{
+		"Cycle {{.Cylce}} done": "can't evaluate field Cylce",
+		"{{.Next.Duration":      "unclosed action",
+		"{{shout .Task}}":       `function "shout" not defined`,
+	} {
+		if _, err := Parse("work_done", text); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q): expected an error containing %q, got %v", text, want, err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package notify turns timer events into sounds and log entries. Each hook
+// is a timer.Handler meant to be passed to Engine.Subscribe.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/logger"
+	"github.com/and1truong/tui-timer/internal/message"
+	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+// TodayFunc reports the focus time and work sessions recorded today.
+type TodayFunc func(now time.Time) (focus time.Duration, pomodoros int)
+
+// Sound plays sounds and voice messages according to cfg. Events mapped to
+// a file in cfg.Sounds.Paths play it instead of the beep. today, if set,
+// fills in the day's totals for the voice messages.
+func Sound(cfg *config.Config, player sound.Player, today TodayFunc) timer.Handler {
+	play := func(event string, enabled bool) {
+		if !enabled {
+			return
+		}
+		switch path := cfg.Sounds.Paths[event]; {
+		case path != "":
+			go player.PlayFile(context.Background(), path)
+		case event == sound.EventTick:
+			go sound.PlayTick(context.Background(), player)
+		case event != sound.EventStart: // starting only plays a file
+			go player.PlayBeep(context.Background())
+		}
+	}
+	voice := func(tmpl *message.Template, evt timer.Event) {
+		if !cfg.Voice.Enabled {
+			return
+		}
+		msg, err := tmpl.Render(messageData(cfg, evt, today))
+		if err == nil && msg != "" {
+			go player.PlayVoice(context.Background(), cfg.Voice.Voice, msg)
+		}
+	}
+
+	return func(evt timer.Event) {
+		switch evt.Type {
+		case timer.EventTick:
+			play(sound.EventTick, cfg.Sounds.Tick)
+		case timer.EventWarning:
+			play(sound.EventWarning, true)
+		case timer.EventStarted:
+			if standalone(evt.Kind) {
+				return
+			}
+			play(sound.EventStart, true)
+			voice(cfg.Voice.Templates.Start, evt)
+		case timer.EventWorkDone, timer.EventCompletedEarly:
+			if evt.Next.Mode == timer.ModeLongBreak && cfg.Sounds.Paths[sound.EventLongBreak] != "" {
+				play(sound.EventLongBreak, cfg.Sounds.Finish)
+			} else {
+				play(sound.EventWorkDone, cfg.Sounds.Finish)
+			}
+			voice(cfg.Voice.Templates.WorkDone, evt)
+		case timer.EventBreakDone:
+			play(sound.EventBreakDone, cfg.Sounds.Break)
+			voice(cfg.Voice.Templates.BreakDone, evt)
+		case timer.EventFinished:
+			play(sound.EventWorkDone, cfg.Sounds.Finish)
+		}
+	}
+}
+
+// messageData describes evt for a voice message.
+func messageData(cfg *config.Config, evt timer.Event, today TodayFunc) message.Data {
+	d := message.Data{
+		Mode:    modeName(evt.Mode),
+		Label:   evt.Label,
+		Cycle:   evt.Cycle,
+		Task:    evt.Task,
+		Planned: message.Duration(evt.Planned),
+		Elapsed: message.Duration(evt.Elapsed),
+	}
+	if evt.Type == timer.EventWorkDone || evt.Type == timer.EventBreakDone || evt.Type == timer.EventCompletedEarly {
+		d.Next = message.Session{
+			Mode:     modeName(evt.Next.Mode),
+			Label:    evt.Next.Label,
+			Duration: message.Duration(evt.Next.Duration),
+		}
+	}
+
+	// Only the classic cycle has a fixed number of sessions per long break.
+	if n := cfg.CyclesBeforeLong; n > 0 && evt.Kind == timer.KindPomodoro && len(cfg.Steps) == 0 {
+		d.UntilLong = n - evt.Cycle%n
+		if evt.Type == timer.EventWorkDone || evt.Type == timer.EventCompletedEarly {
+			d.UntilLong %= n
+		}
+	}
+
+	if today != nil {
+		focus, pomodoros := today(evt.At)
+		// The history is written after the sound plays, so the session
+		// that just ended is not in it yet.
+		if evt.Mode == timer.ModeWork && (evt.Type == timer.EventWorkDone || evt.Type == timer.EventCompletedEarly) {
+			focus += evt.Elapsed
+			pomodoros++
+		}
+		d.Today, d.Pomodoros = message.Duration(focus), pomodoros
+	}
+	return d
+}
+
+// modeName names a mode the way it is spoken.
+func modeName(m timer.Mode) string {
+	return strings.ToLower(m.String())
+}
+
+// Ambient plays a during work sessions: it starts and resumes with them,
+// stops when they are paused, reset or voided, and fades out when they end.
+func Ambient(a *sound.Ambient) timer.Handler {
+	return func(evt timer.Event) {
+		if evt.Mode != timer.ModeWork || standalone(evt.Kind) {
+			return
+		}
+		switch evt.Type {
+		case timer.EventStarted, timer.EventResumed:
+			a.Play()
+		case timer.EventPaused, timer.EventReset, timer.EventVoided:
+			a.Pause()
+		case timer.EventWorkDone, timer.EventCompletedEarly, timer.EventFinished:
+			a.FadeOut()
+		}
+	}
+}
+
+// Log writes a line for every event except ticks, and the full event at the
+// debug level.
+func Log(log *logger.Logger) timer.Handler {
+	return func(evt timer.Event) {
+		if evt.Type == timer.EventTick {
+			return
+		}
+		elapsed := evt.Elapsed.Round(time.Second)
+		log.Debug("Event %s: kind=%d mode=%s label=%q cycle=%d planned=%s elapsed=%s task=%q",
+			evt.Type, evt.Kind, evt.Mode, evt.Label, evt.Cycle, evt.Planned, elapsed, evt.Task)
+		switch evt.Type {
+		case timer.EventStarted:
+			log.Log("Started %s session%s", evt.Label, onTask(evt))
+		case timer.EventPaused:
+			log.Log("Paused %s session at %s", evt.Label, elapsed)
+		case timer.EventResumed:
+			log.Log("Resumed %s session", evt.Label)
+		case timer.EventReset:
+			log.Log("Reset %s session", evt.Label)
+		case timer.EventSkipped:
+			log.Log("Skipped %s after %s", evt.Label, elapsed)
+		case timer.EventCompletedEarly:
+			log.Log("Work session completed early%s (cycle %d, %s of %s, %d interruptions)",
+				onTask(evt), evt.Cycle, elapsed, evt.Planned, len(evt.Interruptions))
+		case timer.EventVoided:
+			reason := evt.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			log.Log("Work session voided%s after %s (%d interruptions): %s", onTask(evt), elapsed, len(evt.Interruptions), reason)
+		case timer.EventAdjusted:
+			log.Log("Adjusted %s session by %s", evt.Label, evt.Delta)
+		case timer.EventWorkDone:
+			log.Log("Work session completed%s (cycle %d, %s, %d interruptions)", onTask(evt), evt.Cycle, elapsed, len(evt.Interruptions))
+		case timer.EventBreakDone:
+			log.Log("%s completed after %s, starting work", evt.Label, elapsed)
+		case timer.EventFinished:
+			label := evt.Label
+			if evt.Kind != timer.KindCountdown {
+				label = "Sequence"
+			}
+			log.Log("%s finished (%s)", label, elapsed)
+		case timer.EventLap:
+			log.Log("Lap %s (total %s)", evt.Delta.Round(time.Millisecond), evt.Elapsed.Round(time.Millisecond))
+		case timer.EventCycleComplete:
+			log.Log("Round complete after cycle %d", evt.Cycle)
+		case timer.EventLongBreakReached:
+			log.Log("Long break reached after cycle %d", evt.Cycle)
+		case timer.EventWarning:
+			log.Log("%s session ends in %s", evt.Label, (evt.Planned - evt.Elapsed).Round(time.Second))
+		case timer.EventInterrupted:
+			in := evt.Interruptions[len(evt.Interruptions)-1]
+			msg := fmt.Sprintf("%s interruption #%d during %s session at %s",
+				in.Kind, len(evt.Interruptions), evt.Label, elapsed)
+			if in.Note != "" {
+				msg += ": " + in.Note
+			}
+			log.Log("%s", msg)
+		case timer.EventTaskChanged:
+			if evt.Task == "" {
+				log.Log("Cleared task %q", evt.PrevTask)
+			} else {
+				log.Log("Task set to %q", evt.Task)
+			}
+		}
+	}
+}
+
+// onTask describes the task of a work session for a log line.
+func onTask(evt timer.Event) string {
+	if evt.Task == "" || evt.Mode != timer.ModeWork {
+		return ""
+	}
+	return fmt.Sprintf(" on %q", evt.Task)
+}
+
+func standalone(k timer.Kind) bool {
+	return k == timer.KindCountdown || k == timer.KindStopwatch
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/message"
+	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+// recorder is a sound.Player that reports what it plays on a channel.
+type recorder chan string
+
+func (r recorder) PlayBeep(context.Context) error { r <- "beep"; return nil }
+func (r recorder) PlayVoice(_ context.Context, _, msg string) error {
+	r <- "voice:" + msg
+	return nil
+}
+func (r recorder) PlayFile(_ context.Context, path string) error { r <- path; return nil }
+
+// played collects what the player was asked to play for evt.
+func played(t *testing.T, h timer.Handler, r recorder, evt timer.Event, n int) map[string]bool {
+	t.Helper()
+	h(evt)
+	got := make(map[string]bool)
+	for range n {
+		select {
+		case s := <-r:
+			got[s] = true
+		case <-time.After(time.Second):
+			t.Fatalf("%v: expected %d sounds, got %v", evt.Type, n, got)
+		}
+	}
+	select {
+	case s := <-r:
+		t.Fatalf("%v: unexpected sound %q", evt.Type, s)
+	case <-time.After(10 * time.Millisecond):
+	}
+	return got
+}
+
+func TestSoundFiles(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Voice.Enabled = false
+	cfg.Sounds.Paths = map[string]string{
+		sound.EventStart:     "start.wav",
+		sound.EventWorkDone:  "work.wav",
+		sound.EventLongBreak: "long.wav",
+	}
+	r := make(recorder, 4)
+	h := Sound(cfg, r, nil)
+
+	for _, tc := range []struct {
+		evt  timer.Event
+		want string
+	}{
+		{timer.Event{Type: timer.EventStarted}, "start.wav"},
+		{timer.Event{Type: timer.EventWorkDone, Next: timer.Step{Mode: timer.ModeShortBreak}}, "work.wav"},
+		{timer.Event{Type: timer.EventWorkDone, Next: timer.Step{Mode: timer.ModeLongBreak}}, "long.wav"},
+		{timer.Event{Type: timer.EventBreakDone}, "beep"},
+		{timer.Event{Type: timer.EventWarning}, "beep"},
+	} {
+		if got := played(t, h, r, tc.evt, 1); !got[tc.want] {
+			t.Errorf("%v: expected %s, got %v", tc.evt.Type, tc.want, got)
+		}
+	}
+
+	cfg.Sounds.Paths = nil
+	played(t, h, r, timer.Event{Type: timer.EventStarted}, 0)
+	cfg.Sounds.Finish = false
+	played(t, h, r, timer.Event{Type: timer.EventWorkDone}, 0)
+}
+
+func TestVoiceMessages(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Sounds.Finish = false
+	var err error
+	if cfg.Voice.Templates.WorkDone, err = message.Parse("work_done",
+		"Cycle {{.Cycle}} done on {{.Task}}, {{.Next.Duration}} {{.Next.Mode}}. "+
+			"{{.UntilLong}} to go, {{.Pomodoros}} today for {{.Today}}."); err != nil {
+		t.Fatal(err)
+	}
+	r := make(recorder, 1)
+	today := func(time.Time) (time.Duration, int) { return 50 * time.Minute, 2 }
+	h := Sound(cfg, r, today)
+
+	evt := timer.Event{
+		Type:    timer.EventWorkDone,
+		Mode:    timer.ModeWork,
+		Cycle:   3,
+		Task:    "Invoicing",
+		Elapsed: 25 * time.Minute,
+		Next:    timer.Step{Mode: timer.ModeShortBreak, Duration: 5 * time.Minute},
+	}
+	want := "voice:Cycle 3 done on Invoicing, 5 minutes short break. 1 to go, 3 today for 1 hour 15 minutes."
+	if got := played(t, h, r, evt, 1); !got[want] {
+		t.Errorf("expected %q, got %v", want, got)
+	}
+
+	evt.Cycle = 4
+	if d := messageData(cfg, evt, nil); d.UntilLong != 0 || d.Today != 0 {
+		t.Errorf("expected the long break next and no totals, got %+v", d)
+	}
+	evt.Type = timer.EventStarted
+	if d := messageData(cfg, evt, nil); d.UntilLong != 4 || d.Next != (message.Session{}) {
+		t.Errorf("expected a new round and no next session, got %+v", d)
+	}
+}
@@ -0,0 +1,183 @@
+package sound
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Streamer is implemented by players that can play audio as it is
+// generated.
+type Streamer interface {
+	// PlayStream plays 16-bit little-endian mono PCM at rate from r until r
+	// ends or ctx is done.
+	PlayStream(ctx context.Context, r io.Reader, rate int) error
+}
+
+// Ambient plays an endless Source in the background, fading it in when it
+// starts and out when it ends.
+type Ambient struct {
+	Volume  float64       // 0 to 1
+	Fade    time.Duration // fade-in and fade-out length
+	OnError func(error)   // called when the player fails, if set
+
+	player Streamer
+	source Source
+	rate   int
+
+	mu      sync.Mutex
+	current *stream
+}
+
+// NewAmbient returns an Ambient playing src, which produces samples at
+// rate, through p.
+func NewAmbient(p Streamer, src Source, rate int) *Ambient {
+	return &Ambient{Volume: 1, player: p, source: src, rate: rate}
+}
+
+// Playing reports whether the sound is playing and not fading out.
+func (a *Ambient) Playing() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current != nil && !a.current.fading()
+}
+
+// Play starts the sound. A sound that is fading out starts over.
+func (a *Ambient) Play() {
+	a.mu.Lock()
+	if a.current != nil && !a.current.fading() {
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	a.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &stream{
+		source: a.source,
+		volume: a.Volume,
+		fade:   int(a.Fade.Seconds() * float64(a.rate)),
+		fadeAt: -1,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	a.mu.Lock()
+	a.current = s
+	a.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		err := a.player.PlayStream(ctx, s, a.rate)
+		a.mu.Lock()
+		if a.current == s {
+			a.current = nil
+		}
+		a.mu.Unlock()
+		if err != nil && ctx.Err() == nil && a.OnError != nil {
+			a.OnError(err)
+		}
+	}()
+}
+
+// Pause stops the sound at once and waits for the player to exit.
+func (a *Ambient) Pause() {
+	a.mu.Lock()
+	s := a.current
+	a.current = nil
+	a.mu.Unlock()
+	if s != nil {
+		s.cancel()
+		<-s.done
+	}
+}
+
+// FadeOut fades the sound out over Fade, after which the player exits.
+func (a *Ambient) FadeOut() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current != nil {
+		a.current.fadeOut()
+	}
+}
+
+// Stop is Pause, for use on shutdown.
+func (a *Ambient) Stop() {
+	a.Pause()
+}
+
+// stream renders a Source as PCM for a Streamer, applying the volume and
+// fades. It ends once a fade-out completes.
+type stream struct {
+	source Source
+	volume float64
+	fade   int // fade length in samples
+	pos    int // samples rendered
+	buf    []float64
+
+	mu     sync.Mutex
+	fadeAt int // sample the fade-out started at, or -1
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *stream) fading() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fadeAt >= 0
+}
+
+func (s *stream) fadeOut() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fadeAt < 0 {
+		s.fadeAt = s.pos
+	}
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	n := len(p) / 2
+	if n == 0 {
+		return 0, nil
+	}
+	if len(s.buf) < n {
+		s.buf = make([]float64, n)
+	}
+	buf := s.buf[:n]
+	s.source.Fill(buf)
+
+	s.mu.Lock()
+	fadeAt := s.fadeAt
+	s.mu.Unlock()
+
+	for i, v := range buf {
+		pos := s.pos + i
+		gain := s.volume
+		if pos < s.fade {
+			gain *= float64(pos) / float64(s.fade)
+		}
+		if fadeAt >= 0 {
+			left := fadeAt + s.fade - pos
+			if left <= 0 {
+				s.advance(i)
+				return 2 * i, io.EOF
+			}
+			gain *= float64(left) / float64(max(s.fade, 1))
+		}
+		v = math.Max(-1, math.Min(1, v*gain))
+		binary.LittleEndian.PutUint16(p[2*i:], uint16(int16(math.Round(v*math.MaxInt16))))
+	}
+	s.advance(n)
+	return 2 * n, nil
+}
+
+// advance moves the position on by n samples. Only Read writes pos, but
+// fadeOut reads it from other goroutines.
+func (s *stream) advance(n int) {
+	s.mu.Lock()
+	s.pos += n
+	s.mu.Unlock()
+}
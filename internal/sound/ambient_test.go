@@ -0,0 +1,114 @@
+package sound
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// constant is a Source of one value.
+type constant float64
+
+func (c constant) Fill(buf []float64) {
+	for i := range buf {
+		buf[i] = float64(c)
+	}
+}
+
+// readSamples reads up to n samples from r.
+func readSamples(t *testing.T, r io.Reader, n int) ([]float64, error) {
+	t.Helper()
+	p := make([]byte, 2*n)
+	got, err := r.Read(p)
+	s := make([]float64, got/2)
+	for i := range s {
+		s[i] = float64(int16(binary.LittleEndian.Uint16(p[2*i:]))) / math.MaxInt16
+	}
+	return s, err
+}
+
+func TestStreamFades(t *testing.T) {
+	s := &stream{source: constant(1), volume: 0.5, fade: 4, fadeAt: -1}
+
+	in, err := readSamples(t, s, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIn := []float64{0, 0.125, 0.25, 0.375, 0.5, 0.5, 0.5, 0.5}
+	for i := range wantIn {
+		if math.Abs(in[i]-wantIn[i]) > 1e-3 {
+			t.Fatalf("expected fade-in %v, got %v", wantIn, in)
+		}
+	}
+
+	s.fadeOut()
+	out, err := readSamples(t, s, 8)
+	if err != io.EOF {
+		t.Fatalf("expected the stream to end after the fade, got %v", err)
+	}
+	wantOut := []float64{0.5, 0.375, 0.25, 0.125}
+	if len(out) != len(wantOut) {
+		t.Fatalf("expected fade-out %v, got %v", wantOut, out)
+	}
+	for i := range wantOut {
+		if math.Abs(out[i]-wantOut[i]) > 1e-3 {
+			t.Fatalf("expected fade-out %v, got %v", wantOut, out)
+		}
+	}
+}
+
+// drain is a Streamer that reads the stream until it ends or is cancelled,
+// then reports how it stopped.
+type drain chan error
+
+func (d drain) PlayStream(ctx context.Context, r io.Reader, rate int) error {
+	p := make([]byte, 64)
+	for ctx.Err() == nil {
+		if _, err := r.Read(p); err == io.EOF {
+			d <- nil
+			return nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+	d <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestAmbient(t *testing.T) {
+	d := make(drain, 1)
+	a := NewAmbient(d, constant(0.2), 1000)
+	a.Fade = 100 * time.Millisecond
+	a.OnError = func(err error) { t.Errorf("unexpected error %v", err) }
+
+	a.Play()
+	a.Play() // already playing
+	if !a.Playing() {
+		t.Fatal("expected the sound to play")
+	}
+	a.Pause()
+	if err := <-d; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected pausing to stop the player, got %v", err)
+	}
+	if a.Playing() {
+		t.Error("expected the sound to stop")
+	}
+
+	a.Play()
+	a.FadeOut()
+	if a.Playing() {
+		t.Error("expected a fading sound not to count as playing")
+	}
+	select {
+	case err := <-d:
+		if err != nil {
+			t.Errorf("expected the stream to end on its own, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the fade-out to end the stream")
+	}
+	a.Stop()
+}
@@ -0,0 +1,39 @@
+package sound
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Backend names accepted by New.
+const (
+	BackendAuto  = "auto"
+	BackendMac   = "mac"
+	BackendLinux = "linux"
+	BackendNone  = "none"
+)
+
+// New returns the Player for backend. BackendAuto, or an empty name, picks
+// the macOS player on macOS and the Linux player elsewhere; the Linux player
+// still beeps when none of its tools are installed.
+func New(backend string) (Player, error) {
+	switch backend {
+	case "", BackendAuto:
+		return auto(runtime.GOOS), nil
+	case BackendMac:
+		return NewMacPlayer(), nil
+	case BackendLinux:
+		return NewLinuxPlayer(), nil
+	case BackendNone:
+		return &NoopPlayer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sound backend %q (want auto, mac, linux or none)", backend)
+	}
+}
+
+func auto(goos string) Player {
+	if goos == "darwin" {
+		return NewMacPlayer()
+	}
+	return NewLinuxPlayer()
+}
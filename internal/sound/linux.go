@@ -0,0 +1,114 @@
+package sound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnavailable is returned when no tool is installed for a kind of sound.
+var ErrUnavailable = errors.New("no sound tool available")
+
+var (
+	// fileTools play sound files, in order of preference.
+	fileTools = []string{"paplay", "pw-play", "aplay"}
+	// voiceTools speak messages, in order of preference.
+	voiceTools = []string{"espeak-ng", "spd-say", "festival"}
+)
+
+// LinuxPlayer implements Player with the PulseAudio, PipeWire or ALSA file
+// players and the espeak-ng, Speech Dispatcher or Festival speech
+// synthesizers, whichever are installed.
+type LinuxPlayer struct {
+	FileTool  string // command used by PlayFile, empty if none
+	VoiceTool string // command used by PlayVoice, empty if none
+}
+
+// NewLinuxPlayer looks up the first available file and voice tools on PATH.
+func NewLinuxPlayer() *LinuxPlayer {
+	return &LinuxPlayer{
+		FileTool:  lookFirst(fileTools),
+		VoiceTool: lookFirst(voiceTools),
+	}
+}
+
+func lookFirst(names []string) string {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func (p *LinuxPlayer) PlayBeep(_ context.Context) error {
+	// Print BEL character for system beep
+	fmt.Print("\a")
+	return nil
+}
+
+// PlayVoice speaks message. voice is passed to the synthesizer if it
+// supports voices; names it does not know, such as the macOS default, fall
+// back to its default voice.
+func (p *LinuxPlayer) PlayVoice(ctx context.Context, voice, message string) error {
+	if p.VoiceTool == "" {
+		return fmt.Errorf("speaking: %w", ErrUnavailable)
+	}
+	if voice != "" {
+		if err := p.speak(ctx, voice, message); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return p.speak(ctx, "", message)
+}
+
+func (p *LinuxPlayer) speak(ctx context.Context, voice, message string) error {
+	var cmd *exec.Cmd
+	switch p.VoiceTool {
+	case "espeak-ng":
+		args := []string{}
+		if voice != "" {
+			args = append(args, "-v", voice)
+		}
+		cmd = exec.CommandContext(ctx, p.VoiceTool, append(args, message)...)
+	case "spd-say":
+		args := []string{"-w"}
+		if voice != "" {
+			args = append(args, "-y", voice)
+		}
+		cmd = exec.CommandContext(ctx, p.VoiceTool, append(args, message)...)
+	case "festival":
+		if voice != "" {
+			return fmt.Errorf("festival: voices are not supported")
+		}
+		cmd = exec.CommandContext(ctx, p.VoiceTool, "--tts")
+		cmd.Stdin = strings.NewReader(message)
+	default:
+		return fmt.Errorf("unknown voice tool %q", p.VoiceTool)
+	}
+	return cmd.Run()
+}
+
+func (p *LinuxPlayer) PlayFile(ctx context.Context, path string) error {
+	switch p.FileTool {
+	case "":
+		return fmt.Errorf("playing %s: %w", path, ErrUnavailable)
+	case "aplay":
+		return exec.CommandContext(ctx, p.FileTool, "-q", path).Run()
+	default:
+		return exec.CommandContext(ctx, p.FileTool, path).Run()
+	}
+}
+
+// PlayStream plays PCM from r with the file tool.
+func (p *LinuxPlayer) PlayStream(ctx context.Context, r io.Reader, rate int) error {
+	if p.FileTool == "" {
+		return fmt.Errorf("streaming: %w", ErrUnavailable)
+	}
+	cmd := exec.CommandContext(ctx, p.FileTool, rawArgs(p.FileTool, rate)...)
+	cmd.Stdin = r
+	return cmd.Run()
+}
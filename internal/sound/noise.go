@@ -0,0 +1,196 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+)
+
+// Source is an endless stream of samples between -1 and 1.
+type Source interface {
+	// Fill overwrites buf with the next len(buf) samples.
+	Fill(buf []float64)
+}
+
+// NoiseColor selects the spectrum of generated noise.
+type NoiseColor int
+
+const (
+	// White noise has equal power at every frequency.
+	White NoiseColor = iota
+	// Pink noise falls off by 3 dB per octave, like rain.
+	Pink
+	// Brown noise falls off by 6 dB per octave, like a distant waterfall.
+	Brown
+)
+
+func (c NoiseColor) String() string {
+	switch c {
+	case White:
+		return "white"
+	case Pink:
+		return "pink"
+	case Brown:
+		return "brown"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseNoise parses a noise color name as used in config files.
+func ParseNoise(s string) (NoiseColor, error) {
+	switch s {
+	case "white":
+		return White, nil
+	case "pink":
+		return Pink, nil
+	case "brown":
+		return Brown, nil
+	default:
+		return White, fmt.Errorf("unknown noise %q (want white, pink or brown)", s)
+	}
+}
+
+// Noise is a Source of white, pink or brown noise.
+type Noise struct {
+	color NoiseColor
+	rng   *rand.Rand
+	b     [7]float64 // filter state
+}
+
+// NewNoise returns a noise generator. The same seed gives the same noise.
+func NewNoise(color NoiseColor, seed uint64) *Noise {
+	return &Noise{color: color, rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (n *Noise) Fill(buf []float64) {
+	b := &n.b
+	for i := range buf {
+		w := n.rng.Float64()*2 - 1
+		var s float64
+		switch n.color {
+		case Pink:
+			// Paul Kellet's refined pink noise filter.
+			b[0] = 0.99886*b[0] + w*0.0555179
+			b[1] = 0.99332*b[1] + w*0.0750759
+			b[2] = 0.96900*b[2] + w*0.1538520
+			b[3] = 0.86650*b[3] + w*0.3104856
+			b[4] = 0.55000*b[4] + w*0.5329522
+			b[5] = -0.7616*b[5] - w*0.0168980
+			s = (b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + w*0.5362) * 0.11
+			b[6] = w * 0.115926
+		case Brown:
+			// Leaky integration of white noise.
+			b[0] = (b[0] + 0.02*w) / 1.02
+			s = b[0] * 3.5
+		default:
+			s = w
+		}
+		buf[i] = math.Max(-1, math.Min(1, s))
+	}
+}
+
+// Loop is a Source that repeats recorded samples.
+type Loop struct {
+	samples []float64
+	pos     int
+}
+
+// NewLoop returns a Source that plays samples over and over.
+func NewLoop(samples []float64) *Loop {
+	return &Loop{samples: samples}
+}
+
+func (l *Loop) Fill(buf []float64) {
+	if len(l.samples) == 0 {
+		clear(buf)
+		return
+	}
+	for i := range buf {
+		buf[i] = l.samples[l.pos]
+		l.pos = (l.pos + 1) % len(l.samples)
+	}
+}
+
+// LoadLoop reads the WAV file at path for a Loop and returns it with its
+// sample rate.
+func LoadLoop(path string) (*Loop, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	samples, rate, err := DecodeWAV(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewLoop(samples), rate, nil
+}
+
+// DecodeWAV reads a 16-bit PCM WAV file, mixing its channels down to mono.
+// It is the inverse of EncodeWAV.
+func DecodeWAV(r io.Reader) (samples []float64, rate int, err error) {
+	var riff struct {
+		RIFF [4]byte
+		Size uint32
+		WAVE [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &riff); err != nil || string(riff.RIFF[:]) != "RIFF" || string(riff.WAVE[:]) != "WAVE" {
+		return nil, 0, errors.New("not a WAV file")
+	}
+
+	var format struct {
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}
+	haveFormat := false
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, 0, errors.New("WAV file has no data")
+		}
+		body := io.LimitReader(r, int64(chunk.Size)+int64(chunk.Size%2))
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if err := binary.Read(body, binary.LittleEndian, &format); err != nil {
+				return nil, 0, fmt.Errorf("reading WAV format: %w", err)
+			}
+			if format.Format != 1 || format.BitsPerSample != 16 || format.Channels == 0 {
+				return nil, 0, errors.New("only 16-bit PCM WAV files are supported")
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, 0, errors.New("WAV data before format")
+			}
+			pcm := make([]int16, chunk.Size/2)
+			if err := binary.Read(r, binary.LittleEndian, pcm); err != nil {
+				return nil, 0, fmt.Errorf("reading WAV data: %w", err)
+			}
+			ch := int(format.Channels)
+			samples = make([]float64, len(pcm)/ch)
+			for i := range samples {
+				var sum float64
+				for _, v := range pcm[i*ch : (i+1)*ch] {
+					sum += float64(v) / math.MaxInt16
+				}
+				samples[i] = sum / float64(ch)
+			}
+			return samples, int(format.SampleRate), nil
+		}
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return nil, 0, err
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sound
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// roughness is the mean absolute difference between neighbouring samples,
+// which falls as the noise gets darker.
+func roughness(s []float64) float64 {
+	var sum float64
+	for i := 1; i < len(s); i++ {
+		sum += math.Abs(s[i] - s[i-1])
+	}
+	return sum / float64(len(s)-1)
+}
+
+func TestNoise(t *testing.T) {
+	var prev float64 = math.Inf(1)
+	for _, color := range []NoiseColor{White, Pink, Brown} {
+		buf := make([]float64, SampleRate)
+		NewNoise(color, 1).Fill(buf)
+
+		var mean, peak float64
+		for _, v := range buf {
+			mean += v / float64(len(buf))
+			peak = math.Max(peak, math.Abs(v))
+		}
+		if peak > 1 || peak < 0.05 {
+			t.Errorf("%v: expected samples within [-1, 1] and audible, peak %v", color, peak)
+		}
+		if math.Abs(mean) > 0.1 {
+			t.Errorf("%v: expected no DC offset, mean %v", color, mean)
+		}
+		r := roughness(buf)
+		if r >= prev {
+			t.Errorf("%v: expected smoother noise than the previous color, got %v >= %v", color, r, prev)
+		}
+		prev = r
+
+		again := make([]float64, len(buf))
+		NewNoise(color, 1).Fill(again)
+		if again[len(again)-1] != buf[len(buf)-1] {
+			t.Errorf("%v: expected the same seed to give the same noise", color)
+		}
+	}
+
+	if _, err := ParseNoise("grey"); err == nil {
+		t.Error("expected an unknown noise to be rejected")
+	}
+}
+
+func TestDecodeWAV(t *testing.T) {
+	in := []float64{0, 0.5, -0.5, 1, -1}
+	var buf bytes.Buffer
+	if err := EncodeWAV(&buf, in, 8000); err != nil {
+		t.Fatal(err)
+	}
+	out, rate, err := DecodeWAV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 8000 || len(out) != len(in) {
+		t.Fatalf("expected %d samples at 8000 Hz, got %d at %d", len(in), len(out), rate)
+	}
+	for i := range in {
+		if math.Abs(out[i]-in[i]) > 1e-4 {
+			t.Errorf("sample %d: expected %v, got %v", i, in[i], out[i])
+		}
+	}
+
+	if _, _, err := DecodeWAV(bytes.NewReader([]byte("ID3 not a wav file"))); err == nil {
+		t.Error("expected a non-WAV file to be rejected")
+	}
+}
+
+func TestLoop(t *testing.T) {
+	l := NewLoop([]float64{1, 2, 3})
+	buf := make([]float64, 4)
+	l.Fill(buf)
+	l.Fill(buf[:2])
+	if buf[0] != 2 || buf[1] != 3 || buf[2] != 3 || buf[3] != 1 {
+		t.Errorf("expected the samples to repeat, got %v", buf)
+	}
+}
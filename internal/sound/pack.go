@@ -0,0 +1,109 @@
+package sound
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sound events that can be mapped to audio files.
+const (
+	EventStart     = "start"
+	EventTick      = "tick"
+	EventWorkDone  = "work_done"
+	EventBreakDone = "break_done"
+	EventLongBreak = "long_break"
+	EventWarning   = "warning"
+)
+
+// Events lists every sound event.
+var Events = []string{EventStart, EventTick, EventWorkDone, EventBreakDone, EventLongBreak, EventWarning}
+
+// ManifestFile is the manifest at the root of a sound pack.
+const ManifestFile = "pack.yaml"
+
+// Pack is a directory of audio files with a manifest mapping sound events
+// to them.
+type Pack struct {
+	Name        string
+	Description string
+	Dir         string
+	Files       map[string]string // sound event to absolute path
+}
+
+type manifest struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Sounds      map[string]string `yaml:"sounds"`
+}
+
+// LoadPack reads the pack in dir. Files in the manifest are relative to dir
+// and must exist.
+func LoadPack(dir string) (*Pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestFile, err)
+	}
+
+	p := &Pack{Name: m.Name, Description: m.Description, Dir: dir, Files: make(map[string]string, len(m.Sounds))}
+	if p.Name == "" {
+		p.Name = filepath.Base(dir)
+	}
+	for event, file := range m.Sounds {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if err := CheckFile(event, file); err != nil {
+			return nil, err
+		}
+		p.Files[event] = file
+	}
+	return p, nil
+}
+
+// ListPacks returns the packs installed as subdirectories of root, sorted
+// by directory name. Directories without a valid manifest are skipped.
+func ListPacks(root string) ([]*Pack, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var packs []*Pack
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if p, err := LoadPack(filepath.Join(root, e.Name())); err == nil {
+			packs = append(packs, p)
+		}
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Dir < packs[j].Dir })
+	return packs, nil
+}
+
+// CheckFile reports an error unless event is a known sound event and path
+// is a regular file.
+func CheckFile(event, path string) error {
+	if !slices.Contains(Events, event) {
+		return fmt.Errorf("unknown sound event %q", event)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", event, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s: %s is not a file", event, path)
+	}
+	return nil
+}
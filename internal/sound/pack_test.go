@@ -0,0 +1,52 @@
+package sound
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePack(t *testing.T, dir, manifest string, files ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "bells")
+	writePack(t, dir, "name: Temple bells\nsounds:\n  work_done: gong.wav\n  warning: ding.ogg\n", "gong.wav", "ding.ogg")
+
+	p, err := LoadPack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Temple bells" || len(p.Files) != 2 {
+		t.Errorf("unexpected pack %+v", p)
+	}
+	if p.Files[EventWarning] != filepath.Join(dir, "ding.ogg") {
+		t.Errorf("expected paths relative to the pack, got %q", p.Files[EventWarning])
+	}
+
+	writePack(t, filepath.Join(root, "broken"), "sounds:\n  work_done: missing.wav\n")
+	writePack(t, filepath.Join(root, "odd"), "sounds:\n  lunch: gong.wav\n", "gong.wav")
+	for _, name := range []string{"broken", "odd"} {
+		if _, err := LoadPack(filepath.Join(root, name)); err == nil {
+			t.Errorf("expected pack %s to be rejected", name)
+		}
+	}
+
+	packs, err := ListPacks(root)
+	if err != nil || len(packs) != 1 || packs[0].Dir != dir {
+		t.Errorf("expected only the valid pack to be listed, got %v/%v", packs, err)
+	}
+}
@@ -3,7 +3,9 @@ package sound
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
 )
 
 // Player is the interface for playing sounds.
@@ -13,6 +15,21 @@ type Player interface {
 	PlayFile(ctx context.Context, path string) error
 }
 
+// Ticker is implemented by players with a lighter sound than PlayBeep for
+// the tick played every second.
+type Ticker interface {
+	PlayTick(ctx context.Context) error
+}
+
+// PlayTick plays the tick of p: PlayTick if p is a Ticker, PlayBeep
+// otherwise.
+func PlayTick(ctx context.Context, p Player) error {
+	if t, ok := p.(Ticker); ok {
+		return t.PlayTick(ctx)
+	}
+	return p.PlayBeep(ctx)
+}
+
 // MacPlayer implements Player using macOS commands.
 type MacPlayer struct{}
 
@@ -36,9 +53,38 @@ func (p *MacPlayer) PlayFile(ctx context.Context, path string) error {
 	return cmd.Run()
 }
 
+// PlayStream plays PCM with SoX's play command, which macOS does not ship.
+func (p *MacPlayer) PlayStream(ctx context.Context, r io.Reader, rate int) error {
+	if _, err := exec.LookPath("play"); err != nil {
+		return fmt.Errorf("streaming: %w (install sox)", ErrUnavailable)
+	}
+	cmd := exec.CommandContext(ctx, "play", rawArgs("play", rate)...)
+	cmd.Stdin = r
+	return cmd.Run()
+}
+
 // NoopPlayer does nothing (for testing or when sound is disabled).
 type NoopPlayer struct{}
 
-func (p *NoopPlayer) PlayBeep(_ context.Context) error                     { return nil }
-func (p *NoopPlayer) PlayVoice(_ context.Context, _, _ string) error       { return nil }
-func (p *NoopPlayer) PlayFile(_ context.Context, _ string) error           { return nil }
+func (p *NoopPlayer) PlayBeep(_ context.Context) error                       { return nil }
+func (p *NoopPlayer) PlayVoice(_ context.Context, _, _ string) error         { return nil }
+func (p *NoopPlayer) PlayFile(_ context.Context, _ string) error             { return nil }
+func (p *NoopPlayer) PlayStream(_ context.Context, _ io.Reader, _ int) error { return nil }
+
+// rawArgs returns the arguments for tool to play 16-bit little-endian mono
+// PCM at rate from stdin.
+func rawArgs(tool string, rate int) []string {
+	r := strconv.Itoa(rate)
+	switch tool {
+	case "paplay":
+		return []string{"--raw", "--format=s16le", "--rate=" + r, "--channels=1"}
+	case "pw-play":
+		return []string{"--format=s16", "--rate=" + r, "--channels=1", "-"}
+	case "aplay":
+		return []string{"-q", "-t", "raw", "-f", "S16_LE", "-r", r, "-c", "1", "-"}
+	case "play":
+		return []string{"-q", "-t", "raw", "-r", r, "-e", "signed", "-b", "16", "-L", "-c", "1", "-"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package sound
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeTools puts shell scripts named after tools on an otherwise empty PATH.
+// Each records its arguments and stdin; a tool named in failing exits 1
+// when given a voice. It returns a function reading the recorded calls.
+func fakeTools(t *testing.T, names ...string) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls.log")
+	for _, name := range names {
+		script := "#!/bin/sh\n" +
+			`echo "` + name + ` $*" >> "` + log + "\"\n" +
+			`case "$*" in *Samantha*) exit 1 ;; esac` + "\n" +
+			`if [ "$1" = "--tts" ]; then read -r line; echo "stdin: $line" >> "` + log + "\"; fi\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		os.Remove(log)
+		if len(data) == 0 {
+			return nil
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+func TestLinuxPlayerDetection(t *testing.T) {
+	tests := []struct {
+		tools       []string
+		file, voice string
+	}{
+		{[]string{"aplay", "pw-play", "paplay", "festival", "espeak-ng"}, "paplay", "espeak-ng"},
+		{[]string{"aplay", "pw-play", "spd-say"}, "pw-play", "spd-say"},
+		{[]string{"aplay", "festival"}, "aplay", "festival"},
+		{nil, "", ""},
+	}
+	for _, tt := range tests {
+		fakeTools(t, tt.tools...)
+		p := NewLinuxPlayer()
+		if p.FileTool != tt.file || p.VoiceTool != tt.voice {
+			t.Errorf("with %v: got %q/%q, want %q/%q", tt.tools, p.FileTool, p.VoiceTool, tt.file, tt.voice)
+		}
+	}
+}
+
+func TestLinuxPlayerCommands(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		tools []string
+		play  func(p *LinuxPlayer) error
+		want  []string
+	}{
+		{[]string{"paplay"}, func(p *LinuxPlayer) error { return p.PlayFile(ctx, "/s/done.wav") }, []string{"paplay /s/done.wav"}},
+		{[]string{"aplay"}, func(p *LinuxPlayer) error { return p.PlayFile(ctx, "/s/done.wav") }, []string{"aplay -q /s/done.wav"}},
+		{[]string{"paplay"}, func(p *LinuxPlayer) error { return p.PlayStream(ctx, strings.NewReader(""), 44100) }, []string{"paplay --raw --format=s16le --rate=44100 --channels=1"}},
+		{[]string{"aplay"}, func(p *LinuxPlayer) error { return p.PlayStream(ctx, strings.NewReader(""), 8000) }, []string{"aplay -q -t raw -f S16_LE -r 8000 -c 1 -"}},
+		{[]string{"espeak-ng"}, func(p *LinuxPlayer) error { return p.PlayVoice(ctx, "en-us", "Break finished") }, []string{"espeak-ng -v en-us Break finished"}},
+		{[]string{"spd-say"}, func(p *LinuxPlayer) error { return p.PlayVoice(ctx, "", "Break finished") }, []string{"spd-say -w Break finished"}},
+		{[]string{"festival"}, func(p *LinuxPlayer) error { return p.PlayVoice(ctx, "", "Break finished") }, []string{"festival --tts", "stdin: Break finished"}},
+		// An unknown voice, like the macOS default, falls back to the default voice.
+		{[]string{"espeak-ng"}, func(p *LinuxPlayer) error { return p.PlayVoice(ctx, "Samantha", "Hi") }, []string{"espeak-ng -v Samantha Hi", "espeak-ng Hi"}},
+		{[]string{"festival"}, func(p *LinuxPlayer) error { return p.PlayVoice(ctx, "Samantha", "Hi") }, []string{"festival --tts", "stdin: Hi"}},
+	}
+	for _, tt := range tests {
+		calls := fakeTools(t, tt.tools...)
+		if err := tt.play(NewLinuxPlayer()); err != nil {
+			t.Errorf("with %v: %v", tt.tools, err)
+		}
+		if got := calls(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("with %v: got %q, want %q", tt.tools, got, tt.want)
+		}
+	}
+}
+
+func TestLinuxPlayerUnavailable(t *testing.T) {
+	fakeTools(t)
+	p := NewLinuxPlayer()
+	if err := p.PlayFile(context.Background(), "x.wav"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+	if err := p.PlayVoice(context.Background(), "", "hi"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	fakeTools(t, "pw-play")
+	linux := &LinuxPlayer{FileTool: "pw-play"}
+
+	for backend, want := range map[string]Player{
+		"linux": linux,
+		"mac":   &MacPlayer{},
+		"none":  &NoopPlayer{},
+	} {
+		got, err := New(backend)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("New(%q) = %#v, %v; want %#v", backend, got, err, want)
+		}
+	}
+	if _, err := New("pulse"); err == nil {
+		t.Error("expected an unknown backend to fail")
+	}
+
+	// auto, the default, depends on the OS.
+	if got := auto("linux"); !reflect.DeepEqual(got, linux) {
+		t.Errorf("auto(linux) = %#v, want %#v", got, linux)
+	}
+	if _, ok := auto("darwin").(*MacPlayer); !ok {
+		t.Error("expected the macOS player on darwin")
+	}
+}
@@ -0,0 +1,176 @@
+package sound
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SampleRate is the rate tones are rendered at, in samples per second.
+const SampleRate = 44100
+
+// Tone is a sine wave shaped by a linear attack and release. A zero
+// frequency is a rest.
+type Tone struct {
+	Freq     float64 // Hz
+	Duration time.Duration
+	Attack   time.Duration // fade-in
+	Release  time.Duration // fade-out
+	Volume   float64       // peak amplitude, 0 to 1
+}
+
+// Chime is a run of tones played one after another.
+type Chime []Tone
+
+// DefaultChime is a short two-note rise.
+var DefaultChime = Chime{
+	{Freq: 880, Duration: 120 * time.Millisecond, Attack: 5 * time.Millisecond, Release: 60 * time.Millisecond, Volume: 0.5},
+	{Freq: 1320, Duration: 180 * time.Millisecond, Attack: 5 * time.Millisecond, Release: 120 * time.Millisecond, Volume: 0.5},
+}
+
+// Samples renders t at rate as amplitudes in [-1, 1].
+func (t Tone) Samples(rate int) []float64 {
+	n := int(t.Duration.Seconds() * float64(rate))
+	out := make([]float64, n)
+	if t.Freq <= 0 {
+		return out
+	}
+	attack := int(t.Attack.Seconds() * float64(rate))
+	release := int(t.Release.Seconds() * float64(rate))
+	for i := range out {
+		env := t.Volume
+		if i < attack {
+			env *= float64(i) / float64(attack)
+		}
+		if left := n - 1 - i; left < release {
+			env *= float64(left) / float64(release)
+		}
+		out[i] = env * math.Sin(2*math.Pi*t.Freq*float64(i)/float64(rate))
+	}
+	return out
+}
+
+// Samples renders the chime at rate.
+func (c Chime) Samples(rate int) []float64 {
+	var out []float64
+	for _, t := range c {
+		out = append(out, t.Samples(rate)...)
+	}
+	return out
+}
+
+// EncodeWAV writes samples as a mono 16-bit PCM WAV file. Samples outside
+// [-1, 1] are clipped.
+func EncodeWAV(w io.Writer, samples []float64, rate int) error {
+	const bits = 16
+	data := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		s = math.Max(-1, math.Min(1, s))
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(int16(math.Round(s*math.MaxInt16))))
+	}
+
+	header := struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF: [4]byte{'R', 'I', 'F', 'F'}, Size: uint32(36 + len(data)),
+		WAVE: [4]byte{'W', 'A', 'V', 'E'}, Fmt: [4]byte{'f', 'm', 't', ' '}, FmtSize: 16,
+		Format: 1, Channels: 1, SampleRate: uint32(rate), ByteRate: uint32(rate * bits / 8),
+		BlockAlign: bits / 8, BitsPerSample: bits,
+		Data: [4]byte{'d', 'a', 't', 'a'}, DataSize: uint32(len(data)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// tonePlayer plays a chime for PlayBeep through the wrapped player's
+// PlayFile.
+type tonePlayer struct {
+	Player
+	chime Chime
+	dir   string // where the chime is rendered; the user cache dir if empty
+
+	once sync.Once
+	path string
+	err  error
+}
+
+// WithTones returns p with PlayBeep replaced by chime, rendered once to a
+// WAV file in the user's cache dir and played with p.PlayFile. If that
+// fails the beep falls back to p.PlayBeep.
+func WithTones(p Player, chime Chime) Player {
+	return &tonePlayer{Player: p, chime: chime}
+}
+
+// PlayTick plays the wrapped player's plain beep: the chime is too long to
+// repeat every second.
+func (p *tonePlayer) PlayTick(ctx context.Context) error {
+	return p.Player.PlayBeep(ctx)
+}
+
+func (p *tonePlayer) PlayBeep(ctx context.Context) error {
+	p.once.Do(func() { p.path, p.err = p.render() })
+	if p.err == nil && p.Player.PlayFile(ctx, p.path) == nil {
+		return nil
+	}
+	return p.Player.PlayBeep(ctx)
+}
+
+// render writes the chime to a file named after its content, so that
+// processes playing the same chime share one file. The file lives in a
+// directory only the user can write to, so no one else can swap it out.
+func (p *tonePlayer) render() (string, error) {
+	dir := p.dir
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cache, "tui-timer")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d %v", SampleRate, p.chime)
+	path := filepath.Join(dir, fmt.Sprintf("chime-%x.wav", h.Sum64()))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	f, err := os.CreateTemp(dir, "chime-*.wav")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if err := EncodeWAV(f, p.chime.Samples(SampleRate), SampleRate); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(f.Name(), path)
+}
@@ -0,0 +1,137 @@
+package sound
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestToneSamples(t *testing.T) {
+	const rate = 8000
+	tone := Tone{Freq: 440, Duration: 500 * time.Millisecond, Attack: 10 * time.Millisecond, Release: 50 * time.Millisecond, Volume: 0.5}
+	s := tone.Samples(rate)
+
+	if len(s) != 4000 {
+		t.Fatalf("expected 4000 samples, got %d", len(s))
+	}
+	if s[0] != 0 || math.Abs(s[len(s)-1]) > 1e-9 {
+		t.Errorf("expected the envelope to start and end silent, got %v and %v", s[0], s[len(s)-1])
+	}
+
+	var peak float64
+	crossings := 0
+	for i, v := range s {
+		peak = math.Max(peak, math.Abs(v))
+		if i > 0 && (s[i-1] < 0) != (v < 0) {
+			crossings++
+		}
+	}
+	if peak > 0.5 || peak < 0.49 {
+		t.Errorf("expected a peak of 0.5, got %v", peak)
+	}
+	// A 440 Hz sine crosses zero twice per cycle: 440 times in half a second.
+	if crossings < 435 || crossings > 445 {
+		t.Errorf("expected about 440 zero crossings, got %d", crossings)
+	}
+}
+
+func TestChimeSamples(t *testing.T) {
+	c := Chime{
+		{Freq: 440, Duration: 100 * time.Millisecond, Volume: 1},
+		{Duration: 50 * time.Millisecond}, // rest
+		{Freq: 880, Duration: 100 * time.Millisecond, Volume: 1},
+	}
+	s := c.Samples(1000)
+	if len(s) != 250 {
+		t.Fatalf("expected 250 samples, got %d", len(s))
+	}
+	for _, v := range s[100:150] {
+		if v != 0 {
+			t.Fatal("expected the rest to be silent")
+		}
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeWAV(&buf, []float64{0, 1, -1, 2}, 8000); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if len(b) != 44+8 {
+		t.Fatalf("expected a 52 byte file, got %d", len(b))
+	}
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" || string(b[36:40]) != "data" {
+		t.Errorf("bad chunk ids %q", b[:40])
+	}
+	le := binary.LittleEndian
+	if le.Uint32(b[4:]) != 44 || le.Uint32(b[24:]) != 8000 || le.Uint32(b[28:]) != 16000 || le.Uint32(b[40:]) != 8 {
+		t.Errorf("bad header fields % x", b[:44])
+	}
+	want := []int16{0, math.MaxInt16, -math.MaxInt16, math.MaxInt16} // 2 is clipped
+	for i, w := range want {
+		if got := int16(le.Uint16(b[44+2*i:])); got != w {
+			t.Errorf("sample %d: got %d, want %d", i, got, w)
+		}
+	}
+}
+
+// recordingPlayer records the files it is asked to play.
+type recordingPlayer struct {
+	NoopPlayer
+	files   []string
+	beeps   int
+	fileErr error
+}
+
+func (p *recordingPlayer) PlayFile(_ context.Context, path string) error {
+	p.files = append(p.files, path)
+	return p.fileErr
+}
+
+func (p *recordingPlayer) PlayBeep(_ context.Context) error {
+	p.beeps++
+	return nil
+}
+
+func TestWithTones(t *testing.T) {
+	inner := &recordingPlayer{}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := WithTones(inner, DefaultChime).(*tonePlayer)
+
+	p.PlayBeep(context.Background())
+	p.PlayBeep(context.Background())
+	if len(inner.files) != 2 || inner.files[0] != inner.files[1] || inner.beeps != 0 {
+		t.Fatalf("expected the same file twice and no bell, got %v / %d", inner.files, inner.beeps)
+	}
+	if filepath.Dir(inner.files[0]) != filepath.Join(cache, "tui-timer") {
+		t.Errorf("expected the chime in the user cache dir, got %s", inner.files[0])
+	}
+	data, err := os.ReadFile(inner.files[0])
+	if err != nil || string(data[:4]) != "RIFF" {
+		t.Errorf("expected a WAV file, got %v", err)
+	}
+
+	// Ticks stay the plain beep.
+	PlayTick(context.Background(), p)
+	if len(inner.files) != 2 || inner.beeps != 1 {
+		t.Errorf("expected a tick to ring the bell, got %v / %d", inner.files, inner.beeps)
+	}
+
+	inner.fileErr = errors.New("no audio device")
+	p.PlayBeep(context.Background())
+	if inner.beeps != 2 {
+		t.Error("expected to fall back to the bell")
+	}
+}
@@ -0,0 +1,73 @@
+// Package state persists the timer engine's session across restarts.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+const stateFile = "state.json"
+
+// Store reads and writes a timer snapshot to a single file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a Store backed by state.json in the XDG state dir.
+func NewStore() (*Store, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreAt(filepath.Join(dir, stateFile)), nil
+}
+
+// NewStoreAt returns a Store backed by the given file.
+func NewStoreAt(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load returns the saved snapshot. ok is false if nothing was saved.
+func (s *Store) Load() (snap timer.Snapshot, ok bool, err error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return snap, false, nil
+		}
+		return snap, false, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, false, err
+	}
+	return snap, true, nil
+}
+
+// Save writes snap atomically, replacing any previous snapshot.
+func (s *Store) Save(snap timer.Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Clear removes the saved snapshot.
+func (s *Store) Clear() error {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := NewStoreAt(filepath.Join(t.TempDir(), "nested", "state.json"))
+
+	if _, ok, err := s.Load(); ok || err != nil {
+		t.Fatalf("expected empty store, got ok=%v err=%v", ok, err)
+	}
+
+	want := timer.Snapshot{
+		Mode:    timer.ModeLongBreak,
+		State:   timer.StatePaused,
+		Elapsed: 3 * time.Minute,
+		Cycle:   4,
+		SavedAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		Interruptions: []timer.Interruption{
+			{Kind: timer.InterruptExternal, Note: "phone", At: time.Date(2024, 1, 1, 8, 55, 0, 0, time.UTC)},
+		},
+	}
+	if err := s.Save(want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok, err := s.Load()
+	if err != nil || !ok {
+		t.Fatalf("load: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.Load(); ok {
+		t.Error("expected snapshot to be cleared")
+	}
+}
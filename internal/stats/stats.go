@@ -0,0 +1,271 @@
+// Package stats aggregates session history into daily, weekly and monthly
+// reports.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/history"
+)
+
+// Period is the size of a reporting bucket.
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+// ParsePeriod validates a period name.
+func ParsePeriod(s string) (Period, error) {
+	switch p := Period(s); p {
+	case Day, Week, Month:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown period %q (want day, week or month)", s)
+	}
+}
+
+// Summary aggregates the work sessions of one period.
+type Summary struct {
+	Period         string        `json:"period"`
+	Start          time.Time     `json:"start,omitzero"`
+	Pomodoros      int           `json:"pomodoros"`
+	Focus          time.Duration `json:"-"`
+	AvgSession     time.Duration `json:"-"`
+	Interruptions  int           `json:"interruptions"`
+	Voided         int           `json:"voided"`
+	CompletionRate float64       `json:"completion_rate"`
+}
+
+func (s *Summary) add(r history.Record) {
+	s.Interruptions += len(r.Interruptions)
+	switch {
+	case r.Outcome.Counts():
+		s.Pomodoros++
+		s.Focus += r.Actual
+	case r.Outcome == history.OutcomeVoided:
+		s.Voided++
+	}
+}
+
+func (s *Summary) finish() {
+	if s.Pomodoros > 0 {
+		s.AvgSession = (s.Focus / time.Duration(s.Pomodoros)).Round(time.Second)
+	}
+	if attempts := s.Pomodoros + s.Voided; attempts > 0 {
+		s.CompletionRate = float64(s.Pomodoros) / float64(attempts)
+	}
+}
+
+// Filter returns the records that started in [since, until). A zero bound
+// is open.
+func Filter(records []history.Record, since, until time.Time) []history.Record {
+	var out []history.Record
+	for _, r := range records {
+		if !since.IsZero() && r.Start.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !r.Start.Before(until) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// FilterTask returns the records spent on task. NoTask selects work
+// sessions without a task.
+func FilterTask(records []history.Record, task string) []history.Record {
+	var out []history.Record
+	for _, r := range records {
+		switch {
+		case r.Task == task && task != "":
+			out = append(out, r)
+		case task == NoTask && r.Task == "" && r.IsWork():
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Group buckets the work sessions in records by period, in loc, oldest
+// first. Periods without work sessions are omitted.
+func Group(records []history.Record, by Period, loc *time.Location) []Summary {
+	buckets := map[time.Time]*Summary{}
+	for _, r := range records {
+		if !r.IsWork() {
+			continue
+		}
+		start := PeriodStart(r.Start.In(loc), by)
+		s, ok := buckets[start]
+		if !ok {
+			s = &Summary{Period: PeriodKey(start, by), Start: start}
+			buckets[start] = s
+		}
+		s.add(r)
+	}
+
+	out := make([]Summary, 0, len(buckets))
+	for _, s := range buckets {
+		s.finish()
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// NoTask labels work sessions without a task in ByTask.
+const NoTask = "(no task)"
+
+// ByTask buckets the work sessions in records by task, most focus time
+// first. Each summary's Period is the task name.
+func ByTask(records []history.Record) []Summary {
+	buckets := map[string]*Summary{}
+	for _, r := range records {
+		if !r.IsWork() {
+			continue
+		}
+		task := r.Task
+		if task == "" {
+			task = NoTask
+		}
+		s, ok := buckets[task]
+		if !ok {
+			s = &Summary{Period: task}
+			buckets[task] = s
+		}
+		s.add(r)
+	}
+
+	out := make([]Summary, 0, len(buckets))
+	for _, s := range buckets {
+		s.finish()
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Focus != out[j].Focus {
+			return out[i].Focus > out[j].Focus
+		}
+		return out[i].Period < out[j].Period
+	})
+	return out
+}
+
+// Total aggregates all work sessions in records into one summary.
+func Total(records []history.Record) Summary {
+	s := Summary{Period: "total"}
+	for _, r := range records {
+		if r.IsWork() {
+			s.add(r)
+		}
+	}
+	s.finish()
+	return s
+}
+
+// PeriodStart returns the start of the period containing t. Weeks start on
+// Monday.
+func PeriodStart(t time.Time, by Period) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch by {
+	case Week:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+// PeriodKey formats the period starting at start, e.g. 2024-01-31,
+// 2024-W05 or 2024-01.
+func PeriodKey(start time.Time, by Period) string {
+	switch by {
+	case Week:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Month:
+		return start.Format("2006-01")
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+// WriteTable prints summaries followed by a total row. heading names the
+// first column.
+func WriteTable(w io.Writer, heading string, rows []Summary, total Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\tPOMODOROS\tFOCUS\tAVG\tINTERRUPTIONS\tVOIDED\tCOMPLETION\t\n", strings.ToUpper(heading))
+	for _, s := range append(rows, total) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\t%.0f%%\t\n",
+			s.Period, s.Pomodoros, formatHours(s.Focus), formatHours(s.AvgSession),
+			s.Interruptions, s.Voided, s.CompletionRate*100)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON prints summaries and the total as a JSON document. Durations
+// are in seconds.
+func WriteJSON(w io.Writer, rows []Summary, total Summary) error {
+	type jsonSummary struct {
+		Summary
+		Focus      int64 `json:"focus_seconds"`
+		AvgSession int64 `json:"avg_session_seconds"`
+	}
+	conv := func(s Summary) jsonSummary {
+		return jsonSummary{Summary: s, Focus: int64(s.Focus.Seconds()), AvgSession: int64(s.AvgSession.Seconds())}
+	}
+
+	doc := struct {
+		Periods []jsonSummary `json:"periods"`
+		Total   jsonSummary   `json:"total"`
+	}{Periods: make([]jsonSummary, 0, len(rows)), Total: conv(total)}
+	for _, s := range rows {
+		doc.Periods = append(doc.Periods, conv(s))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func formatHours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// DailyFocus sums the focus time of counted work sessions per day in loc,
+// keyed by the start of the day.
+func DailyFocus(records []history.Record, loc *time.Location) map[time.Time]time.Duration {
+	daily := map[time.Time]time.Duration{}
+	for _, r := range records {
+		if r.IsWork() && r.Outcome.Counts() {
+			daily[PeriodStart(r.Start.In(loc), Day)] += r.Actual
+		}
+	}
+	return daily
+}
+
+// Streak counts the consecutive days with focus time up to today. A today
+// without any yet does not break the streak.
+func Streak(daily map[time.Time]time.Duration, today time.Time) int {
+	day := PeriodStart(today, Day)
+	if daily[day] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	n := 0
+	for daily[day] > 0 {
+		n++
+		day = day.AddDate(0, 0, -1)
+	}
+	return n
+}
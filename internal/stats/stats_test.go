@@ -0,0 +1,197 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+func work(start time.Time, actual time.Duration, outcome history.Outcome, interruptions int) history.Record {
+	r := history.Record{
+		Mode:    "work",
+		Planned: 25 * time.Minute,
+		Actual:  actual,
+		Start:   start,
+		End:     start.Add(actual),
+		Outcome: outcome,
+	}
+	for i := 0; i < interruptions; i++ {
+		r.Interruptions = append(r.Interruptions, timer.Interruption{Kind: timer.InterruptExternal, At: start})
+	}
+	return r
+}
+
+func testRecords() []history.Record {
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	return []history.Record{
+		work(day(1, 9), 25*time.Minute, history.OutcomeCompleted, 1),
+		work(day(1, 10), 15*time.Minute, history.OutcomeCompletedEarly, 0),
+		work(day(1, 11), 5*time.Minute, history.OutcomeVoided, 2),
+		{Mode: "short_break", Actual: 5 * time.Minute, Start: day(1, 12), Outcome: history.OutcomeCompleted},
+		work(day(3, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+		work(day(8, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+	}
+}
+
+func TestGroupByDay(t *testing.T) {
+	got := Group(testRecords(), Day, time.UTC)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 days, got %d: %+v", len(got), got)
+	}
+
+	first := got[0]
+	if first.Period != "2024-01-01" || first.Pomodoros != 2 || first.Voided != 1 {
+		t.Errorf("unexpected first day %+v", first)
+	}
+	if first.Focus != 40*time.Minute || first.AvgSession != 20*time.Minute {
+		t.Errorf("expected 40m focus averaging 20m, got %v / %v", first.Focus, first.AvgSession)
+	}
+	if first.Interruptions != 3 {
+		t.Errorf("expected 3 interruptions, got %d", first.Interruptions)
+	}
+	if first.CompletionRate < 0.66 || first.CompletionRate > 0.67 {
+		t.Errorf("expected 2/3 completion rate, got %v", first.CompletionRate)
+	}
+}
+
+func TestGroupByWeekAndMonth(t *testing.T) {
+	weeks := Group(testRecords(), Week, time.UTC)
+	if len(weeks) != 2 || weeks[0].Period != "2024-W01" || weeks[1].Period != "2024-W02" {
+		t.Fatalf("unexpected weeks %+v", weeks)
+	}
+	if weeks[0].Pomodoros != 3 || weeks[1].Pomodoros != 1 {
+		t.Errorf("unexpected weekly pomodoros %d, %d", weeks[0].Pomodoros, weeks[1].Pomodoros)
+	}
+
+	months := Group(testRecords(), Month, time.UTC)
+	if len(months) != 1 || months[0].Period != "2024-01" || months[0].Pomodoros != 4 {
+		t.Fatalf("unexpected months %+v", months)
+	}
+}
+
+func TestPeriodStartWeekBeginsMonday(t *testing.T) {
+	sunday := time.Date(2024, 1, 7, 23, 0, 0, 0, time.UTC)
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := PeriodStart(sunday, Week); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	got := Filter(testRecords(), since, until)
+	if len(got) != 1 || got[0].Start.Day() != 3 {
+		t.Fatalf("expected only the 3rd, got %+v", got)
+	}
+	if got := Filter(testRecords(), time.Time{}, time.Time{}); len(got) != len(testRecords()) {
+		t.Errorf("open bounds should keep everything, got %d", len(got))
+	}
+}
+
+func TestWriteTableAndJSON(t *testing.T) {
+	recs := testRecords()
+	rows := Group(recs, Day, time.UTC)
+	total := Total(recs)
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, "period", rows, total); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"PERIOD", "2024-01-01", "0h40m", "67%", "total", "1h30m"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, rows, total); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Periods []map[string]any `json:"periods"`
+		Total   map[string]any   `json:"total"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(doc.Periods) != 3 || doc.Total["pomodoros"] != float64(4) || doc.Total["focus_seconds"] != float64(5400) {
+		t.Errorf("unexpected JSON %s", buf.String())
+	}
+}
+
+func TestDailyFocusAndStreak(t *testing.T) {
+	daily := DailyFocus(testRecords(), time.UTC)
+	if got := daily[time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)]; got != 40*time.Minute {
+		t.Errorf("expected 40m on the 1st (voided and breaks excluded), got %v", got)
+	}
+
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	recs := []history.Record{
+		work(day(5, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+		work(day(6, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+		work(day(7, 9), 25*time.Minute, history.OutcomeVoided, 0),
+		work(day(8, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+		work(day(9, 9), 25*time.Minute, history.OutcomeCompleted, 0),
+	}
+	daily = DailyFocus(recs, time.UTC)
+
+	tests := []struct {
+		today time.Time
+		want  int
+	}{
+		{day(9, 18), 2}, // the voided 7th breaks the streak
+		{day(10, 8), 2}, // nothing yet today
+		{day(11, 8), 0}, // missed yesterday
+		{day(6, 18), 2},
+	}
+	for _, tt := range tests {
+		if got := Streak(daily, tt.today); got != tt.want {
+			t.Errorf("Streak(%v) = %d, want %d", tt.today, got, tt.want)
+		}
+	}
+}
+
+func TestByTask(t *testing.T) {
+	recs := testRecords()
+	recs[0].Task = "Invoicing"
+	recs[1].Task = "Client A"
+	recs[4].Task = "Client A"
+
+	got := ByTask(recs)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 tasks, got %+v", got)
+	}
+	want := []struct {
+		task  string
+		focus time.Duration
+	}{
+		{"Client A", 40 * time.Minute},
+		{NoTask, 25 * time.Minute},
+		{"Invoicing", 25 * time.Minute},
+	}
+	for i, w := range want {
+		if got[i].Period != w.task || got[i].Focus != w.focus {
+			t.Errorf("row %d: expected %s %v, got %s %v", i, w.task, w.focus, got[i].Period, got[i].Focus)
+		}
+	}
+}
+
+func TestFilterTask(t *testing.T) {
+	recs := testRecords()
+	recs[0].Task = "Invoicing"
+
+	if got := FilterTask(recs, "Invoicing"); len(got) != 1 || got[0].Task != "Invoicing" {
+		t.Errorf("unexpected task records %+v", got)
+	}
+	// The break is not a work session without a task.
+	if got := FilterTask(recs, NoTask); len(got) != 4 {
+		t.Errorf("expected 4 work sessions without a task, got %d", len(got))
+	}
+}
@@ -0,0 +1,138 @@
+// Package tasks keeps the Pomodoro task list: what to work on, how many
+// pomodoros each task was estimated at and how many it actually took.
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/and1truong/tui-timer/internal/config"
+)
+
+const tasksFile = "tasks.json"
+
+// Task is one entry of the task list.
+type Task struct {
+	Title    string `json:"title"`
+	Estimate int    `json:"estimate,omitempty"` // estimated pomodoros
+	Actual   int    `json:"actual,omitempty"`   // completed pomodoros
+	Done     bool   `json:"done,omitempty"`
+
+	ref string // the source's handle: a todo.txt line or a Taskwarrior UUID
+}
+
+// Over reports whether the task took more pomodoros than estimated.
+func (t Task) Over() bool {
+	return t.Estimate > 0 && t.Actual > t.Estimate
+}
+
+// Source loads and saves a task list, in order.
+type Source interface {
+	Load() ([]Task, error)
+	Save([]Task) error
+}
+
+// ErrChanged is returned by Source.Save when the tasks were changed
+// elsewhere in a way the saved list does not reflect. Load again to see
+// them.
+var ErrChanged = errors.New("tasks changed elsewhere")
+
+// Find returns the index of the first open task titled title, or -1.
+func Find(list []Task, title string) int {
+	if title == "" {
+		return -1
+	}
+	for i, t := range list {
+		if !t.Done && t.Title == title {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add appends an open task titled title. Blank titles are ignored.
+func Add(list []Task, title string) []Task {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return list
+	}
+	return append(list, Task{Title: title})
+}
+
+// Move shifts the task at i by delta places, clamped to the list, and
+// returns its new index.
+func Move(list []Task, i, delta int) int {
+	j := i + delta
+	if j < 0 {
+		j = 0
+	}
+	if j > len(list)-1 {
+		j = len(list) - 1
+	}
+	t := list[i]
+	if j > i {
+		copy(list[i:j], list[i+1:j+1])
+	} else {
+		copy(list[j+1:i+1], list[j:i])
+	}
+	list[j] = t
+	return j
+}
+
+// DefaultPath returns tasks.json in the XDG data dir.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, tasksFile), nil
+}
+
+// Store is a Source backed by a JSON file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the task list. A missing file is an empty list.
+func (s *Store) Load() ([]Task, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var doc struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Tasks, nil
+}
+
+// Save writes list atomically, replacing the previous one.
+func (s *Store) Save(list []Task) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(struct {
+		Tasks []Task `json:"tasks"`
+	}{list}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
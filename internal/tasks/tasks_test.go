@@ -0,0 +1,74 @@
+package tasks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func titles(list []Task) []string {
+	var out []string
+	for _, t := range list {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "data", "tasks.json"))
+
+	if list, err := s.Load(); err != nil || len(list) != 0 {
+		t.Fatalf("expected empty list, got %v/%v", list, err)
+	}
+
+	want := []Task{
+		{Title: "Write report", Estimate: 3, Actual: 1},
+		{Title: "Email", Done: true},
+	}
+	if err := s.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestAddFindMove(t *testing.T) {
+	var list []Task
+	list = Add(list, " a ")
+	list = Add(list, "")
+	list = Add(list, "b")
+	list = Add(list, "c")
+	if got := titles(list); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected list %v", got)
+	}
+
+	if j := Move(list, 0, 2); j != 2 || !reflect.DeepEqual(titles(list), []string{"b", "c", "a"}) {
+		t.Errorf("move down: got %d %v", j, titles(list))
+	}
+	if j := Move(list, 2, -1); j != 1 || !reflect.DeepEqual(titles(list), []string{"b", "a", "c"}) {
+		t.Errorf("move up: got %d %v", j, titles(list))
+	}
+	if j := Move(list, 0, -1); j != 0 {
+		t.Errorf("expected move past the top to clamp, got %d", j)
+	}
+
+	list[0].Done = true
+	list = Add(list, "b")
+	if i := Find(list, "b"); i != 3 {
+		t.Errorf("expected Find to skip done tasks, got %d", i)
+	}
+	if i := Find(list, "zzz"); i != -1 {
+		t.Errorf("expected -1, got %d", i)
+	}
+}
+
+func TestOver(t *testing.T) {
+	if (Task{Estimate: 2, Actual: 3}).Over() != true || (Task{Actual: 3}).Over() != false {
+		t.Error("unexpected Over")
+	}
+}
@@ -0,0 +1,232 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+var (
+	uuidRe     = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	pomodoroRe = regexp.MustCompile(`^Pomodoro (\d+)\b`)
+)
+
+// Taskwarrior is a Source backed by the Taskwarrior CLI. Pending tasks
+// matching Filter make up the list, most urgent first. Its Handler starts
+// and stops the task being worked on and annotates it with each completed
+// pomodoro. Estimates and ordering are not written back.
+type Taskwarrior struct {
+	// Bin is the task binary, "task" on PATH by default.
+	Bin string
+	// Filter narrows the tasks listed, e.g. "project:work +next".
+	Filter string
+
+	known   map[string]Task   // by UUID, as of the last Load or Save
+	uuids   map[string]string // UUID by title
+	counts  map[string]int    // annotated pomodoros by UUID
+	started string            // UUID of the task we started, if any
+}
+
+// NewTaskwarrior returns a Taskwarrior using filter.
+func NewTaskwarrior(filter string) *Taskwarrior {
+	return &Taskwarrior{Bin: "task", Filter: filter}
+}
+
+// twTask is the part of `task export` that we read.
+type twTask struct {
+	UUID        string  `json:"uuid"`
+	Description string  `json:"description"`
+	Status      string  `json:"status"`
+	Urgency     float64 `json:"urgency"`
+	Annotations []struct {
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// pomodoros is the highest pomodoro count annotated on the task.
+func (t twTask) pomodoros() int {
+	n := 0
+	for _, a := range t.Annotations {
+		if m := pomodoroRe.FindStringSubmatch(a.Description); m != nil {
+			var k int
+			fmt.Sscan(m[1], &k)
+			n = max(n, k)
+		}
+	}
+	return n
+}
+
+// Load exports the pending tasks.
+func (s *Taskwarrior) Load() ([]Task, error) {
+	args := append(strings.Fields(s.Filter), "status:pending", "export")
+	out, err := s.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var exported []twTask
+	if err := json.Unmarshal(out, &exported); err != nil {
+		return nil, fmt.Errorf("reading task export: %w", err)
+	}
+	sort.SliceStable(exported, func(i, j int) bool { return exported[i].Urgency > exported[j].Urgency })
+
+	list := make([]Task, 0, len(exported))
+	s.counts = map[string]int{}
+	for _, t := range exported {
+		list = append(list, Task{Title: t.Description, Actual: t.pomodoros(), ref: t.UUID})
+		s.counts[t.UUID] = t.pomodoros()
+	}
+	s.remember(list)
+	return list, nil
+}
+
+// Save adds new tasks and completes or reopens tasks whose Done changed.
+// Tasks no longer in list are only dropped from it; Taskwarrior keeps them,
+// and they are listed again on the next Load.
+func (s *Taskwarrior) Save(list []Task) error {
+	var errs []error
+	for i, t := range list {
+		if t.ref == "" {
+			out, err := s.run("rc.verbose=new-uuid", "add", t.Title)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			list[i].ref = uuidRe.FindString(string(out))
+			continue
+		}
+		switch prev := s.known[t.ref]; {
+		case t.Done && !prev.Done:
+			_, err := s.run(t.ref, "done")
+			errs = append(errs, err)
+		case !t.Done && prev.Done:
+			_, err := s.run(t.ref, "modify", "status:pending")
+			errs = append(errs, err)
+		}
+	}
+	s.remember(list)
+	return errors.Join(errs...)
+}
+
+func (s *Taskwarrior) remember(list []Task) {
+	s.known = map[string]Task{}
+	s.uuids = map[string]string{}
+	for _, t := range list {
+		if t.ref == "" {
+			continue
+		}
+		s.known[t.ref] = t
+		if _, ok := s.uuids[t.Title]; !ok && !t.Done {
+			s.uuids[t.Title] = t.ref
+		}
+	}
+}
+
+// Handler returns a timer.Handler that keeps Taskwarrior in step with the
+// work sessions on a listed task: started while the session runs, stopped
+// when it pauses or ends, and annotated with each completed pomodoro.
+// Errors are passed to onError.
+func (s *Taskwarrior) Handler(onError func(error)) timer.Handler {
+	return func(evt timer.Event) {
+		if evt.Mode != timer.ModeWork && evt.Type != timer.EventTaskChanged {
+			return
+		}
+		var err error
+		switch evt.Type {
+		case timer.EventStarted, timer.EventResumed:
+			err = s.start(s.uuids[evt.Task])
+		case timer.EventPaused, timer.EventReset, timer.EventVoided:
+			err = s.Stop()
+		case timer.EventWorkDone, timer.EventCompletedEarly:
+			err = errors.Join(s.Stop(), s.annotate(evt))
+		case timer.EventFinished:
+			// The last session of a one-shot plan is a pomodoro too; a
+			// standalone countdown is not.
+			err = s.Stop()
+			if evt.Kind != timer.KindCountdown && evt.Kind != timer.KindStopwatch {
+				err = errors.Join(err, s.annotate(evt))
+			}
+		case timer.EventTaskChanged:
+			if s.started != "" {
+				err = errors.Join(s.Stop(), s.start(s.uuids[evt.Task]))
+			}
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Resume starts the task of a work session that e restored as running.
+// Engine.Restore emits no events, so the Handler does not see the session
+// start again after Stop ran on the last exit.
+func (s *Taskwarrior) Resume(e *timer.Engine) error {
+	if e.State != timer.StateRunning || e.Mode != timer.ModeWork {
+		return nil
+	}
+	return s.start(s.uuids[e.Task])
+}
+
+func (s *Taskwarrior) start(uuid string) error {
+	if uuid == "" || uuid == s.started {
+		return nil
+	}
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	if _, err := s.run(uuid, "start"); err != nil {
+		return err
+	}
+	s.started = uuid
+	return nil
+}
+
+// Stop stops the task started by the Handler, if any. Call it on exit so
+// that a running session does not leave the task active.
+func (s *Taskwarrior) Stop() error {
+	if s.started == "" {
+		return nil
+	}
+	uuid := s.started
+	s.started = ""
+	_, err := s.run(uuid, "stop")
+	return err
+}
+
+func (s *Taskwarrior) annotate(evt timer.Event) error {
+	uuid := s.uuids[evt.Task]
+	if uuid == "" {
+		return nil
+	}
+	if s.counts == nil {
+		s.counts = map[string]int{}
+	}
+	s.counts[uuid]++
+	note := fmt.Sprintf("Pomodoro %d (%s)", s.counts[uuid], evt.Elapsed.Round(time.Second))
+	_, err := s.run(uuid, "annotate", note)
+	return err
+}
+
+// run calls the task binary without confirmation prompts or chatter.
+func (s *Taskwarrior) run(args ...string) ([]byte, error) {
+	args = append([]string{"rc.confirmation=off", "rc.verbose=nothing"}, args...)
+	cmd := exec.Command(s.Bin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return out, fmt.Errorf("task %s: %s", strings.Join(args[2:], " "), msg)
+	}
+	return out, nil
+}
@@ -0,0 +1,194 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+const fakeTask = `#!/bin/sh
+echo "$@" >> "$TASK_LOG"
+case "$*" in
+*export*) cat "$TASK_EXPORT" ;;
+*" add "*) echo "Created task 99999999-2222-3333-4444-555555555555." ;;
+*broken*) echo "No such task." >&2; exit 1 ;;
+esac
+`
+
+const exportFixture = `[
+  {"id":1,"uuid":"11111111-2222-3333-4444-555555555555","description":"Write report","status":"pending","urgency":2.5,
+   "annotations":[{"entry":"20240101T090000Z","description":"Pomodoro 1 (25m0s)"},{"entry":"20240101T100000Z","description":"Pomodoro 2 (25m0s)"}]},
+  {"id":2,"uuid":"aaaaaaaa-2222-3333-4444-555555555555","description":"Pay invoices","status":"pending","urgency":8}
+]`
+
+// fakeTaskwarrior puts a fake task binary on PATH and returns a function
+// that reads the commands it was called with.
+func fakeTaskwarrior(t *testing.T) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "task"), []byte(fakeTask), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	export := filepath.Join(dir, "export.json")
+	if err := os.WriteFile(export, []byte(exportFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "calls.log")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("TASK_EXPORT", export)
+	t.Setenv("TASK_LOG", log)
+
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		os.Remove(log)
+		var calls []string
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if line != "" {
+				calls = append(calls, strings.TrimPrefix(line, "rc.confirmation=off rc.verbose=nothing "))
+			}
+		}
+		return calls
+	}
+}
+
+func TestTaskwarriorLoad(t *testing.T) {
+	calls := fakeTaskwarrior(t)
+	s := NewTaskwarrior("project:work")
+
+	list, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Title: "Pay invoices", ref: "aaaaaaaa-2222-3333-4444-555555555555"},
+		{Title: "Write report", Actual: 2, ref: "11111111-2222-3333-4444-555555555555"},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("expected %+v, got %+v", want, list)
+	}
+	if got := calls(); !reflect.DeepEqual(got, []string{"project:work status:pending export"}) {
+		t.Errorf("unexpected calls %q", got)
+	}
+}
+
+func TestTaskwarriorSave(t *testing.T) {
+	calls := fakeTaskwarrior(t)
+	s := NewTaskwarrior("")
+	list, _ := s.Load()
+	calls()
+
+	// Dropping Write report from the list must not delete it.
+	list[0].Done = true
+	list = Add(list[:1], "New task")
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"aaaaaaaa-2222-3333-4444-555555555555 done",
+		"rc.verbose=new-uuid add New task",
+	}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if list[1].ref != "99999999-2222-3333-4444-555555555555" {
+		t.Errorf("expected the new task's UUID, got %q", list[1].ref)
+	}
+
+	// Nothing changed, nothing to run.
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls(); len(got) != 0 {
+		t.Errorf("expected no calls, got %q", got)
+	}
+}
+
+func TestTaskwarriorHandler(t *testing.T) {
+	calls := fakeTaskwarrior(t)
+	s := NewTaskwarrior("")
+	s.Load()
+	calls()
+
+	var errs []error
+	h := s.Handler(func(err error) { errs = append(errs, err) })
+	work := func(typ timer.EventType, task string) {
+		h(timer.Event{Type: typ, Mode: timer.ModeWork, Task: task, Elapsed: 25 * time.Minute})
+	}
+	const report = "11111111-2222-3333-4444-555555555555"
+	const invoices = "aaaaaaaa-2222-3333-4444-555555555555"
+
+	work(timer.EventStarted, "Write report")
+	work(timer.EventPaused, "Write report")
+	work(timer.EventResumed, "Write report")
+	work(timer.EventTaskChanged, "Pay invoices")
+	work(timer.EventWorkDone, "Pay invoices")
+	h(timer.Event{Type: timer.EventStarted, Mode: timer.ModeShortBreak, Task: "Pay invoices"})
+	work(timer.EventStarted, "Not in Taskwarrior")
+	work(timer.EventWorkDone, "Write report")
+	work(timer.EventStarted, "Pay invoices")
+	work(timer.EventFinished, "Pay invoices")
+
+	want := []string{
+		report + " start",
+		report + " stop",
+		report + " start",
+		report + " stop",
+		invoices + " start",
+		invoices + " stop",
+		invoices + " annotate Pomodoro 1 (25m0s)",
+		report + " annotate Pomodoro 3 (25m0s)",
+		invoices + " start",
+		invoices + " stop",
+		invoices + " annotate Pomodoro 2 (25m0s)",
+	}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestTaskwarriorResume(t *testing.T) {
+	calls := fakeTaskwarrior(t)
+	s := NewTaskwarrior("")
+	s.Load()
+	calls()
+
+	e := timer.New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
+	snap := timer.Snapshot{Mode: timer.ModeWork, State: timer.StatePaused, Elapsed: 5 * time.Minute, SavedAt: time.Now(), Task: "Write report"}
+	e.Restore(snap)
+	if err := s.Resume(e); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls(); len(got) != 0 {
+		t.Errorf("expected a paused session to leave the task alone, got %q", got)
+	}
+
+	snap.State = timer.StateRunning
+	e.Restore(snap)
+	if err := s.Resume(e); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"11111111-2222-3333-4444-555555555555 start"}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTaskwarriorErrors(t *testing.T) {
+	fakeTaskwarrior(t)
+	s := NewTaskwarrior("")
+	s.uuids = map[string]string{"Broken": "broken"}
+
+	var errs []error
+	s.Handler(func(err error) { errs = append(errs, err) })(timer.Event{Type: timer.EventStarted, Mode: timer.ModeWork, Task: "Broken"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "No such task.") {
+		t.Errorf("expected the task error, got %v", errs)
+	}
+}
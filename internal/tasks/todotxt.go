@@ -0,0 +1,271 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	doneRe     = regexp.MustCompile(`^x (\d{4}-\d{2}-\d{2} )?`)
+	priorityRe = regexp.MustCompile(`^\(([A-Z])\) `)
+	dateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+)
+
+// TodoTxt is a Source backed by a todo.txt file. Open tasks become the task
+// list; completed tasks and blank lines are left alone. Pomodoros are
+// written back as a pomo:N tag and estimates as est:N.
+type TodoTxt struct {
+	path string
+	now  func() time.Time
+
+	// owned counts the lines handed out by the last Load or Save, so that
+	// Save can tell the task lines it manages from the rest of the file.
+	owned map[string]int
+}
+
+// NewTodoTxt returns a TodoTxt backed by the file at path.
+func NewTodoTxt(path string) *TodoTxt {
+	return &TodoTxt{path: path, now: time.Now}
+}
+
+// Load reads the open tasks from the file. A missing file has none.
+func (s *TodoTxt) Load() ([]Task, error) {
+	lines, err := s.readLines()
+	if err != nil {
+		return nil, err
+	}
+	var list []Task
+	s.owned = map[string]int{}
+	for _, line := range lines {
+		if !isTodo(line) {
+			continue
+		}
+		list = append(list, parseTodo(line))
+		s.owned[line]++
+	}
+	return list, nil
+}
+
+// Save writes list back to the file. Lines of tasks from the last Load
+// are rewritten in list order, lines of tasks no longer in list are
+// removed and new tasks are appended; every other line is kept as it is.
+// A task whose line was changed by someone else since it was loaded is
+// found again by its title, keeping the other edit; if its title changed
+// too, the other edit wins and the task is not written. Save returns
+// ErrChanged when that happens or when the file gained tasks from
+// elsewhere. The file is replaced atomically.
+func (s *TodoTxt) Save(list []Task) error {
+	lines, err := s.readLines()
+	if err != nil {
+		return err
+	}
+
+	present := map[string]int{}
+	for _, line := range lines {
+		present[line]++
+	}
+	owned := s.owned
+	if owned == nil {
+		owned = map[string]int{}
+	}
+	changed := false
+	var out []string
+	for i := range list {
+		if ref := list[i].ref; ref != "" && present[ref] == 0 {
+			line, ok := rematch(lines, present, owned, ref)
+			if !ok {
+				changed = true
+				continue
+			}
+			owned[line]++
+			list[i].ref = line
+			list[i].Done = list[i].Done || doneRe.MatchString(line)
+		}
+		if list[i].ref != "" {
+			present[list[i].ref]--
+		}
+		list[i].ref = s.format(list[i])
+		out = append(out, list[i].ref)
+	}
+
+	// Fill the slots of the lines we own with the new task lines. Open
+	// tasks in lines we do not own were added elsewhere.
+	var file []string
+	next := 0
+	for _, line := range lines {
+		if owned[line] == 0 {
+			if isTodo(line) {
+				changed = true
+			}
+			file = append(file, line)
+			continue
+		}
+		owned[line]--
+		if next < len(out) {
+			file = append(file, out[next])
+			next++
+		}
+	}
+	file = append(file, out[next:]...)
+
+	s.owned = map[string]int{}
+	for _, line := range out {
+		s.owned[line]++
+	}
+	if err := s.write(file); err != nil {
+		return err
+	}
+	if changed {
+		return ErrChanged
+	}
+	return nil
+}
+
+// rematch finds a line of someone else's with the same title as ref, the
+// line a task was loaded from.
+func rematch(lines []string, present, owned map[string]int, ref string) (string, bool) {
+	title := parseTodo(ref).Title
+	for _, line := range lines {
+		if present[line] > owned[line] && strings.TrimSpace(line) != "" && parseTodo(line).Title == title {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// isTodo reports whether line is an open task.
+func isTodo(line string) bool {
+	return strings.TrimSpace(line) != "" && !doneRe.MatchString(line)
+}
+
+// parseTodo reads a todo.txt task line.
+func parseTodo(line string) Task {
+	t := Task{ref: line, Done: doneRe.MatchString(line)}
+	var desc []string
+	for _, field := range strings.Fields(description(line)) {
+		key, value, ok := strings.Cut(field, ":")
+		n, err := strconv.Atoi(value)
+		switch {
+		case ok && key == "pomo" && err == nil:
+			t.Actual = n
+		case ok && key == "est" && err == nil:
+			t.Estimate = n
+		default:
+			desc = append(desc, field)
+		}
+	}
+	t.Title = strings.Join(desc, " ")
+	return t
+}
+
+// description strips the completion mark, priority and dates from a line.
+func description(line string) string {
+	line = doneRe.ReplaceAllString(line, "")
+	line = priorityRe.ReplaceAllString(line, "")
+	return dateRe.ReplaceAllString(line, "")
+}
+
+// format renders t as a todo.txt line, starting from the line it was read
+// from so that priorities, dates, projects, contexts and other tags are
+// kept.
+func (s *TodoTxt) format(t Task) string {
+	line := t.ref
+	if line == "" {
+		line = t.Title
+	}
+	line = setTag(line, "pomo", t.Actual)
+	line = setTag(line, "est", t.Estimate)
+
+	done := doneRe.MatchString(line)
+	switch {
+	case t.Done && !done:
+		// Completed tasks lose their priority, kept as a pri: tag.
+		if m := priorityRe.FindStringSubmatch(line); m != nil {
+			line = priorityRe.ReplaceAllString(line, "") + " pri:" + m[1]
+		}
+		line = fmt.Sprintf("x %s %s", s.now().Format("2006-01-02"), line)
+	case !t.Done && done:
+		line = doneRe.ReplaceAllString(line, "")
+	}
+	return line
+}
+
+// setTag sets key:n on line, removing it when n is zero.
+func setTag(line, key string, n int) string {
+	if n == 0 {
+		re := regexp.MustCompile(`(^|\s+)` + key + `:\d+(\s|$)`)
+		return strings.TrimSpace(re.ReplaceAllString(line, "${2}"))
+	}
+	re := regexp.MustCompile(`(^|\s)` + key + `:\d+(\s|$)`)
+	tag := fmt.Sprintf("%s:%d", key, n)
+	if re.MatchString(line) {
+		return re.ReplaceAllString(line, "${1}"+tag+"${2}")
+	}
+	return line + " " + tag
+}
+
+func (s *TodoTxt) readLines() ([]string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, strings.TrimSuffix(sc.Text(), "\r"))
+	}
+	return lines, sc.Err()
+}
+
+// write replaces the file atomically, keeping its permissions.
+func (s *TodoTxt) write(lines []string) error {
+	// Replace the file a symlink points to, not the link, so that a synced
+	// todo.txt linked into place keeps getting updates.
+	path := s.path
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".todo-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
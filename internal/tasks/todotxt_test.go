@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const todoFixture = `(A) 2024-01-01 Write report +work @desk est:3 due:2024-01-10
+x 2024-01-02 Old task pomo:2
+
+Call the bank @phone pomo:1
+`
+
+func newTestTodo(t *testing.T, content string) (*TodoTxt, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTodoTxt(path)
+	s.now = func() time.Time { return time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC) }
+	return s, path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestTodoTxtLoad(t *testing.T) {
+	s, _ := newTestTodo(t, todoFixture)
+	list, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 open tasks, got %+v", list)
+	}
+	if got := list[0]; got.Title != "Write report +work @desk due:2024-01-10" || got.Estimate != 3 || got.Actual != 0 {
+		t.Errorf("unexpected first task %+v", got)
+	}
+	if got := list[1]; got.Title != "Call the bank @phone" || got.Actual != 1 {
+		t.Errorf("unexpected second task %+v", got)
+	}
+}
+
+func TestTodoTxtSaveWritesBack(t *testing.T) {
+	s, path := newTestTodo(t, todoFixture)
+	list, _ := s.Load()
+
+	list[0].Actual = 2
+	list[1].Done = true
+	list = Add(list, "New thing")
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `(A) 2024-01-01 Write report +work @desk est:3 due:2024-01-10 pomo:2
+x 2024-01-02 Old task pomo:2
+
+x 2024-01-05 Call the bank @phone pomo:1
+New thing
+`
+	if got := readFile(t, path); got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o600 {
+		t.Errorf("expected permissions to be kept, got %v", fi.Mode().Perm())
+	}
+
+	// Saving again updates the lines just written.
+	list[0].Actual = 3
+	list[0].Done = true
+	list[2].Estimate = 1
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	want = `x 2024-01-05 2024-01-01 Write report +work @desk est:3 due:2024-01-10 pomo:3 pri:A
+x 2024-01-02 Old task pomo:2
+
+x 2024-01-05 Call the bank @phone pomo:1
+New thing est:1
+`
+	if got := readFile(t, path); got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTodoTxtSaveReorderAndDelete(t *testing.T) {
+	s, path := newTestTodo(t, "a\n\nb\nx 2024-01-01 done\nc\n")
+	list, _ := s.Load()
+
+	// Drop "b" and move "c" to the top.
+	list = []Task{list[2], list[0]}
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	want := "c\n\na\nx 2024-01-01 done\n"
+	if got := readFile(t, path); got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTodoTxtKeepsOutsideEdits(t *testing.T) {
+	s, path := newTestTodo(t, "a\nb\nc @home\n")
+	list, _ := s.Load()
+
+	// Someone edits the file in another editor while the timer runs: "a"
+	// gets a priority, "c" a new context and a task is added.
+	if err := os.WriteFile(path, []byte("(A) a\nb\nc @work\nd\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i := range list {
+		list[i].Actual = 1
+	}
+	if err := s.Save(list); !errors.Is(err, ErrChanged) {
+		t.Fatalf("expected ErrChanged, got %v", err)
+	}
+	if got, want := readFile(t, path), "(A) a pomo:1\nb pomo:1\nc @work\nd\n"; got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Loading again lists them, and the next pomodoro is kept.
+	list, _ = s.Load()
+	if len(list) != 4 || list[2].Title != "c @work" || list[3].Title != "d" {
+		t.Fatalf("expected the outside edits to be listed, got %+v", list)
+	}
+	list[0].Actual = 2
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, path), "(A) a pomo:2\nb pomo:1\nc @work\nd\n"; got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTodoTxtFollowsSymlink(t *testing.T) {
+	s, synced := newTestTodo(t, "Call the bank\n")
+	link := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.Symlink(synced, link); err != nil {
+		t.Skip(err)
+	}
+	s.path = link
+
+	list, _ := s.Load()
+	list[0].Actual = 1
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the symlink to stay in place, got %v/%v", fi, err)
+	}
+	if got := readFile(t, synced); got != "Call the bank pomo:1\n" {
+		t.Errorf("expected the linked file to be updated, got %q", got)
+	}
+}
+
+func TestSetTag(t *testing.T) {
+	tests := []struct {
+		line string
+		n    int
+		want string
+	}{
+		{"task", 2, "task pomo:2"},
+		{"task pomo:1 +p", 2, "task pomo:2 +p"},
+		{"task pomo:1 +p", 0, "task +p"},
+		{"pomo:1 task", 0, "task"},
+		{"task pomodoro:1", 3, "task pomodoro:1 pomo:3"},
+	}
+	for _, tt := range tests {
+		if got := setTag(tt.line, "pomo", tt.n); got != tt.want {
+			t.Errorf("setTag(%q, %d) = %q, want %q", tt.line, tt.n, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package timer
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/and1truong/tui-timer/internal/clock"
+)
 
 // Mode represents the current timer mode.
 type Mode int
@@ -24,6 +29,70 @@ func (m Mode) String() string {
 	}
 }
 
+// ParseMode parses a mode name as used in config files.
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "work":
+		return ModeWork, nil
+	case "short_break", "break":
+		return ModeShortBreak, nil
+	case "long_break":
+		return ModeLongBreak, nil
+	default:
+		return ModeWork, fmt.Errorf("unknown mode %q", s)
+	}
+}
+
+// Step is one session of a custom sequence.
+type Step struct {
+	Mode     Mode
+	Duration time.Duration
+	Label    string
+}
+
+// Kind selects how the engine schedules sessions.
+type Kind int
+
+const (
+	// KindPomodoro counts fixed-length sessions down.
+	KindPomodoro Kind = iota
+	// KindFlowtime counts work up until Finish is called, then earns a break
+	// sized from the work length.
+	KindFlowtime
+	// KindCountdown is a single countdown with no cycles or breaks.
+	KindCountdown
+	// KindStopwatch counts up indefinitely and records laps.
+	KindStopwatch
+)
+
+// Bracket maps work lengths up to UpTo to a break of length Break.
+type Bracket struct {
+	UpTo  time.Duration
+	Break time.Duration
+}
+
+// FlowPolicy decides how long a Flowtime break is.
+type FlowPolicy struct {
+	Ratio    float64   // break = work * Ratio, used when no bracket matches
+	Brackets []Bracket // checked in order; the first with work <= UpTo wins
+}
+
+// Break returns the break earned by a work session of the given length.
+func (p FlowPolicy) Break(work time.Duration) time.Duration {
+	for _, b := range p.Brackets {
+		if work <= b.UpTo {
+			return b.Break
+		}
+	}
+	if p.Ratio > 0 {
+		return time.Duration(float64(work) * p.Ratio).Round(time.Second)
+	}
+	if n := len(p.Brackets); n > 0 {
+		return p.Brackets[n-1].Break
+	}
+	return 0
+}
+
 // State represents the timer's running state.
 type State int
 
@@ -33,32 +102,83 @@ const (
 	StatePaused
 )
 
-// Event is emitted when notable things happen.
-type Event int
-
-const (
-	EventNone Event = iota
-	EventTick
-	EventWorkDone
-	EventBreakDone
-	EventStarted
-)
-
-// Engine is the core timer logic, decoupled from any TUI or clock.
+// Engine is the core timer logic, decoupled from any TUI.
+//
+// The countdown is anchored to the wall clock: while running, Remaining is
+// derived from the time the current run segment started rather than from
+// the number of ticks received, so late ticks or a suspended machine do not
+// stretch a session.
 type Engine struct {
 	WorkDuration     time.Duration
 	ShortBreak       time.Duration
 	LongBreak        time.Duration
 	CyclesBeforeLong int
 
+	Kind       Kind
+	FlowPolicy FlowPolicy
+
+	// AutoStartBreaks and AutoStartWork start the next session on their own
+	// after AutoStartDelay instead of waiting for Toggle.
+	AutoStartBreaks bool
+	AutoStartWork   bool
+	AutoStartDelay  time.Duration
+
+	// Sequence, when set, replaces the work/short/long pattern. Sessions run
+	// in order and the sequence loops after its last step unless Once is set,
+	// in which case finishing the last step emits EventFinished.
+	Sequence []Step
+	Step     int // index into Sequence
+	Once     bool
+
+	// Until, when set on a countdown, pins its end to a wall-clock time.
+	// Pausing does not move the deadline.
+	Until time.Time
+
+	// FitUntil, when set on a one-shot sequence, is the deadline its steps
+	// were planned for with PlanUntil. The steps left are planned again
+	// whenever the timer is idle or paused, so late starts and pauses do not
+	// push the end past it. Once it has passed, no new session starts. The
+	// plan uses WorkDuration, ShortBreak, LongBreak and CyclesBeforeLong.
+	FitUntil time.Time
+
 	Mode      Mode
 	State     State
 	Remaining time.Duration
 	Cycle     int // completed work cycles
+
+	// LastElapsed is the actual length of the most recently ended session.
+	LastElapsed time.Duration
+
+	Laps []time.Duration // stopwatch lap lengths, oldest first
+
+	Interruptions []Interruption // recorded during the current session
+
+	// Task names what the work sessions are spent on. It carries over from
+	// session to session until changed with SetTask.
+	Task string
+
+	// WarnBefore, when set, emits EventWarning once a running session longer
+	// than WarnBefore has that much time left.
+	WarnBefore time.Duration
+
+	clock     clock.Clock
+	startedAt time.Time     // start of the current run segment
+	elapsed   time.Duration // time run before the current segment
+
+	autoStartAt  time.Time     // pending auto-start, zero if none
+	flowBreak    time.Duration // break earned by the last Flowtime work session
+	sessionStart time.Time     // when the current session was first started
+	warned       bool          // EventWarning was emitted for this session
+
+	subs   []subscriber
+	nextID int
 }
 
-// New creates a new timer engine.
-func New(work, shortBreak, longBreak time.Duration, cyclesBeforeLong int) *Engine {
+// New creates a new timer engine. A nil clk uses the system clock.
+func New(work, shortBreak, longBreak time.Duration, cyclesBeforeLong int, clk clock.Clock) *Engine {
+	if clk == nil {
+		clk = clock.Real{}
+	}
 	return &Engine{
 		WorkDuration:     work,
 		ShortBreak:       shortBreak,
@@ -67,62 +187,358 @@ func New(work, shortBreak, longBreak time.Duration, cyclesBeforeLong int) *Engin
 		Mode:             ModeWork,
 		State:            StateIdle,
 		Remaining:        work,
+		clock:            clk,
 	}
 }
 
-// Toggle starts or pauses the timer. Returns EventStarted on first start.
+// NewSequence creates a timer engine that runs steps in order.
+func NewSequence(steps []Step, clk clock.Clock) *Engine {
+	e := New(0, 0, 0, 0, clk)
+	e.Sequence = steps
+	if len(steps) > 0 {
+		e.Mode = steps[0].Mode
+		e.Remaining = steps[0].Duration
+	}
+	return e
+}
+
+// NewFlowtime creates a timer engine in Flowtime mode.
+func NewFlowtime(policy FlowPolicy, clk clock.Clock) *Engine {
+	e := New(0, 0, 0, 0, clk)
+	e.Kind = KindFlowtime
+	e.FlowPolicy = policy
+	return e
+}
+
+// NewCountdown creates a single countdown of length d.
+func NewCountdown(d time.Duration, clk clock.Clock) *Engine {
+	e := New(d, 0, 0, 0, clk)
+	e.Kind = KindCountdown
+	return e
+}
+
+// NewUntil creates a countdown that ends at the wall-clock time until.
+func NewUntil(until time.Time, clk clock.Clock) *Engine {
+	e := NewCountdown(0, clk)
+	e.Until = until
+	e.retarget(e.clock.Now())
+	return e
+}
+
+// NewFitUntil creates a one-shot sequence of as many Pomodoro cycles as fit
+// before until; see FitUntil. If not even a minute of work fits, it is a
+// plain Until countdown.
+func NewFitUntil(until time.Time, work, shortBreak, longBreak time.Duration, cyclesBeforeLong int, clk clock.Clock) *Engine {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	steps := PlanUntil(clk.Now(), until, work, shortBreak, longBreak, cyclesBeforeLong)
+	if len(steps) == 0 {
+		return NewUntil(until, clk)
+	}
+	e := NewSequence(steps, clk)
+	e.Once = true
+	e.FitUntil = until
+	e.WorkDuration, e.ShortBreak, e.LongBreak, e.CyclesBeforeLong = work, shortBreak, longBreak, cyclesBeforeLong
+	return e
+}
+
+// PlanUntil lays out as many Pomodoro cycles as fit between from and until.
+// The last work session is shortened to end at until, and a break that would
+// leave no room for more work is dropped.
+func PlanUntil(from, until time.Time, work, shortBreak, longBreak time.Duration, cyclesBeforeLong int) []Step {
+	return planUntil(from, until, work, shortBreak, longBreak, cyclesBeforeLong, 0)
+}
+
+// planUntil is PlanUntil after cycles completed work sessions, which decide
+// where the long breaks fall.
+func planUntil(from, until time.Time, work, shortBreak, longBreak time.Duration, cyclesBeforeLong, cycles int) []Step {
+	var steps []Step
+	left := until.Sub(from)
+	for cycle := cycles + 1; left >= time.Minute; cycle++ {
+		w := work
+		label := ""
+		if left < w {
+			w = left
+			label = "Final"
+		}
+		steps = append(steps, Step{Mode: ModeWork, Duration: w, Label: label})
+		left -= w
+
+		mode, brk := ModeShortBreak, shortBreak
+		if cyclesBeforeLong > 0 && cycle%cyclesBeforeLong == 0 {
+			mode, brk = ModeLongBreak, longBreak
+		}
+		if left-brk < time.Minute {
+			break
+		}
+		steps = append(steps, Step{Mode: mode, Duration: brk})
+		left -= brk
+	}
+	return steps
+}
+
+// NewStopwatch creates a stopwatch.
+func NewStopwatch(clk clock.Clock) *Engine {
+	e := New(0, 0, 0, 0, clk)
+	e.Kind = KindStopwatch
+	return e
+}
+
+// CountsUp reports whether the current session counts up rather than down.
+func (e *Engine) CountsUp() bool {
+	return e.Kind == KindStopwatch || (e.Kind == KindFlowtime && e.Mode == ModeWork)
+}
+
+// Lap records a stopwatch lap and returns its length. It does nothing
+// unless a stopwatch is running.
+func (e *Engine) Lap() (time.Duration, bool) {
+	if e.Kind != KindStopwatch || e.State != StateRunning {
+		return 0, false
+	}
+	var prev time.Duration
+	for _, l := range e.Laps {
+		prev += l
+	}
+	lap := e.Elapsed() - prev
+	e.Laps = append(e.Laps, lap)
+
+	evt := e.event(EventLap)
+	evt.Delta = lap
+	e.emit(evt)
+	return lap, true
+}
+
+// Label returns the name of the current session: the step label for a
+// sequence, the mode name otherwise.
+func (e *Engine) Label() string {
+	switch e.Kind {
+	case KindCountdown:
+		if !e.Until.IsZero() {
+			return "Until " + e.Until.Format("15:04")
+		}
+		return "Countdown"
+	case KindStopwatch:
+		return "Stopwatch"
+	}
+	if len(e.Sequence) > 0 && e.Sequence[e.Step].Label != "" {
+		return e.Sequence[e.Step].Label
+	}
+	return e.Mode.String()
+}
+
+// Toggle starts, pauses or resumes the timer and returns EventStarted,
+// EventPaused or EventResumed accordingly. It returns EventNone once a
+// FitUntil plan is over.
 func (e *Engine) Toggle() Event {
+	now := e.clock.Now()
 	switch e.State {
 	case StateIdle:
-		e.State = StateRunning
-		return EventStarted
+		if e.fitOver(now) {
+			return Event{Type: EventNone}
+		}
+		e.start(now)
+		return e.emit(e.event(EventStarted))
 	case StateRunning:
+		e.sync(now)
 		e.State = StatePaused
-	case StatePaused:
-		e.State = StateRunning
+		return e.emit(e.event(EventPaused))
+	default:
+		e.start(now)
+		return e.emit(e.event(EventResumed))
 	}
-	return EventNone
 }
 
 // Reset resets the current session to its full duration.
-func (e *Engine) Reset() {
+func (e *Engine) Reset() Event {
+	evt := e.event(EventReset)
+	e.rewind()
+	return e.emit(evt)
+}
+
+// Void abandons the current work session without counting it, recording
+// reason on the emitted EventVoided. The session starts over from its full
+// length. It returns EventNone unless a work session has been started.
+func (e *Engine) Void(reason string) Event {
+	if e.Mode != ModeWork || e.State == StateIdle || e.Kind == KindCountdown || e.Kind == KindStopwatch {
+		return Event{Type: EventNone}
+	}
+	if e.State == StateRunning {
+		e.sync(e.clock.Now())
+	}
+	evt := e.event(EventVoided)
+	evt.Reason = reason
+	e.rewind()
+	return e.emit(evt)
+}
+
+// rewind returns the current session to its idle, full-length state.
+func (e *Engine) rewind() {
 	e.State = StateIdle
+	e.Laps = nil
+	e.Interruptions = nil
+	e.autoStartAt = time.Time{}
+	e.sessionStart = time.Time{}
+	e.elapsed = 0
+	e.warned = false
 	e.Remaining = e.currentDuration()
+	e.retarget(e.clock.Now())
 }
 
-// Skip moves to the next session.
+// warn emits EventWarning the first time the running session gets within
+// WarnBefore of its end. Time added back with AdjustTime re-arms it.
+func (e *Engine) warn() {
+	if e.WarnBefore <= 0 || e.CountsUp() || e.currentDuration() <= e.WarnBefore {
+		return
+	}
+	if e.Remaining > e.WarnBefore {
+		e.warned = false
+		return
+	}
+	if !e.warned {
+		e.warned = true
+		e.emit(e.event(EventWarning))
+	}
+}
+
+// Elapsed returns how long the current session has been running, excluding
+// time spent paused.
+func (e *Engine) Elapsed() time.Duration {
+	if e.State == StateRunning {
+		return e.elapsed + e.clock.Now().Sub(e.startedAt)
+	}
+	return e.elapsed
+}
+
+// Deadline returns the wall-clock time at which the current session ends.
+// It is only meaningful while the timer is running.
+func (e *Engine) Deadline() (time.Time, bool) {
+	if e.State != StateRunning {
+		return time.Time{}, false
+	}
+	return e.startedAt.Add(e.currentDuration() - e.elapsed), true
+}
+
+// AutoStartIn reports how long until the pending auto-start fires.
+func (e *Engine) AutoStartIn() (time.Duration, bool) {
+	if e.autoStartAt.IsZero() {
+		return 0, false
+	}
+	d := e.autoStartAt.Sub(e.clock.Now())
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// CancelAutoStart drops a pending auto-start. Returns false if none was
+// pending.
+func (e *Engine) CancelAutoStart() bool {
+	if e.autoStartAt.IsZero() {
+		return false
+	}
+	e.autoStartAt = time.Time{}
+	return true
+}
+
+// Skip ends the current break and moves on to work, returning
+// EventSkipped. Work sessions cannot be skipped; use Finish or Void.
 func (e *Engine) Skip() Event {
-	return e.advance()
+	if e.Mode == ModeWork {
+		return Event{Type: EventNone}
+	}
+	return e.advance(EventSkipped)
 }
 
-// Tick decrements the timer by 1 second. Returns the event that occurred.
+// Finish ends the current work session now and counts it. A Pomodoro ended
+// before its time emits EventCompletedEarly; ending a Flowtime session is
+// its normal completion and emits EventWorkDone. It returns EventNone unless
+// a work session has been started.
+func (e *Engine) Finish() Event {
+	switch {
+	case e.Mode != ModeWork || e.State == StateIdle || e.Kind == KindStopwatch:
+		return Event{Type: EventNone}
+	case e.Kind == KindFlowtime || e.Kind == KindCountdown:
+		return e.advance(EventNone)
+	default:
+		return e.advance(EventCompletedEarly)
+	}
+}
+
+// Tick recomputes Remaining from the clock. Returns the event that occurred.
+// If the deadline passed while no ticks were delivered (e.g. the machine was
+// asleep), the session is completed on the next tick.
 func (e *Engine) Tick() Event {
+	now := e.clock.Now()
+	if e.fitOver(now) {
+		e.autoStartAt = time.Time{}
+	}
+	if e.State == StateIdle && !e.autoStartAt.IsZero() && !now.Before(e.autoStartAt) {
+		e.start(now)
+		return e.emit(e.event(EventStarted))
+	}
 	if e.State != StateRunning {
-		return EventNone
+		e.retarget(now)
+		return Event{Type: EventNone}
 	}
 
-	e.Remaining -= time.Second
-	if e.Remaining <= 0 {
-		return e.advance()
+	e.sync(now)
+	if e.Remaining <= 0 && !e.CountsUp() {
+		return e.advance(EventNone)
 	}
-	return EventTick
+	e.warn()
+	return e.emit(e.event(EventTick))
 }
 
 // AdjustTime adds delta to both Remaining and the current mode's duration.
-// Remaining is clamped to [1s, currentDuration].
-func (e *Engine) AdjustTime(delta time.Duration) {
-	switch e.Mode {
-	case ModeWork:
+// Remaining is clamped to [1s, currentDuration]. Returns EventAdjusted, or
+// EventNone for sessions that count up and for a FitUntil plan, whose
+// lengths come from its deadline.
+func (e *Engine) AdjustTime(delta time.Duration) Event {
+	if e.CountsUp() || !e.FitUntil.IsZero() {
+		return Event{Type: EventNone}
+	}
+	e.adjust(delta)
+	evt := e.event(EventAdjusted)
+	evt.Delta = delta
+	return e.emit(evt)
+}
+
+func (e *Engine) adjust(delta time.Duration) {
+	if !e.Until.IsZero() {
+		e.Until = e.Until.Add(delta)
+		if e.State == StateRunning {
+			e.sync(e.clock.Now())
+		}
+		e.retarget(e.clock.Now())
+		return
+	}
+	if e.State == StateRunning {
+		e.sync(e.clock.Now())
+	}
+	switch {
+	case e.Kind == KindFlowtime:
+		e.flowBreak += delta
+		if e.flowBreak < time.Minute {
+			e.flowBreak = time.Minute
+		}
+	case len(e.Sequence) > 0:
+		step := &e.Sequence[e.Step]
+		step.Duration += delta
+		if step.Duration < time.Minute {
+			step.Duration = time.Minute
+		}
+	case e.Mode == ModeWork:
 		e.WorkDuration += delta
 		if e.WorkDuration < time.Minute {
 			e.WorkDuration = time.Minute
 		}
-	case ModeShortBreak:
+	case e.Mode == ModeShortBreak:
 		e.ShortBreak += delta
 		if e.ShortBreak < time.Minute {
 			e.ShortBreak = time.Minute
 		}
-	case ModeLongBreak:
+	case e.Mode == ModeLongBreak:
 		e.LongBreak += delta
 		if e.LongBreak < time.Minute {
 			e.LongBreak = time.Minute
@@ -135,6 +551,77 @@ func (e *Engine) AdjustTime(delta time.Duration) {
 	if max := e.currentDuration(); e.Remaining > max {
 		e.Remaining = max
 	}
+	e.elapsed = e.currentDuration() - e.Remaining
+}
+
+// Snapshot is a serializable copy of the engine's session state.
+type Snapshot struct {
+	Kind    Kind          `json:"kind"`
+	Mode    Mode          `json:"mode"`
+	State   State         `json:"state"`
+	Elapsed time.Duration `json:"elapsed"`
+	Cycle   int           `json:"cycle"`
+	Step    int           `json:"step,omitempty"`
+	Break   time.Duration `json:"break,omitempty"` // earned Flowtime break
+	Started time.Time     `json:"started,omitempty"`
+	SavedAt time.Time     `json:"saved_at"`
+	Task    string        `json:"task,omitempty"`
+
+	Interruptions []Interruption `json:"interruptions,omitempty"`
+}
+
+// Snapshot captures the current session state.
+func (e *Engine) Snapshot() Snapshot {
+	now := e.clock.Now()
+	elapsed := e.elapsed
+	if e.State == StateRunning {
+		elapsed += now.Sub(e.startedAt)
+	}
+	return Snapshot{
+		Kind:    e.Kind,
+		Mode:    e.Mode,
+		State:   e.State,
+		Elapsed: elapsed,
+		Cycle:   e.Cycle,
+		Step:    e.Step,
+		Break:   e.flowBreak,
+		Started: e.sessionStart,
+		SavedAt: now,
+		Task:    e.Task,
+
+		Interruptions: e.Interruptions,
+	}
+}
+
+// Restore loads a snapshot taken by Snapshot. A session that was running
+// keeps running from SavedAt, so time spent while the process was gone is
+// counted and an expired session completes on the next Tick. Snapshots of a
+// different Kind are ignored and Restore returns false.
+func (e *Engine) Restore(s Snapshot) bool {
+	if s.Kind != e.Kind {
+		return false
+	}
+	e.Mode = s.Mode
+	e.State = s.State
+	e.Cycle = s.Cycle
+	if n := len(e.Sequence); n > 0 {
+		e.Step = s.Step % n
+		e.Mode = e.Sequence[e.Step].Mode
+	}
+	e.flowBreak = s.Break
+	e.Interruptions = s.Interruptions
+	e.Task = s.Task
+	e.sessionStart = s.Started
+	e.elapsed = s.Elapsed
+	if d := e.currentDuration(); e.elapsed > d && !e.CountsUp() {
+		e.elapsed = d
+	}
+	e.Remaining = e.currentDuration() - e.elapsed
+	if e.State == StateRunning {
+		e.startedAt = s.SavedAt
+		e.sync(e.clock.Now())
+	}
+	return true
 }
 
 // Progress returns a value from 0.0 to 1.0.
@@ -148,6 +635,18 @@ func (e *Engine) Progress() float64 {
 }
 
 func (e *Engine) currentDuration() time.Duration {
+	if e.Kind == KindStopwatch {
+		return 0
+	}
+	if e.Kind == KindFlowtime {
+		if e.Mode == ModeWork {
+			return 0
+		}
+		return e.flowBreak
+	}
+	if len(e.Sequence) > 0 {
+		return e.Sequence[e.Step].Duration
+	}
 	switch e.Mode {
 	case ModeWork:
 		return e.WorkDuration
@@ -160,13 +659,135 @@ func (e *Engine) currentDuration() time.Duration {
 	}
 }
 
-func (e *Engine) advance() Event {
-	var evt Event
+func (e *Engine) start(now time.Time) {
+	if e.State == StateIdle {
+		e.sessionStart = now
+	}
+	e.retarget(now)
+	e.State = StateRunning
+	e.startedAt = now
+	e.autoStartAt = time.Time{}
+}
 
-	switch e.Mode {
-	case ModeWork:
+// retarget resizes an Until countdown so that it ends at Until, and plans
+// a FitUntil sequence again so that it ends at FitUntil.
+func (e *Engine) retarget(now time.Time) {
+	if !e.FitUntil.IsZero() {
+		e.refit(now)
+		return
+	}
+	if e.Until.IsZero() {
+		return
+	}
+	left := e.Until.Sub(now)
+	if left < 0 {
+		left = 0
+	}
+	e.WorkDuration = e.elapsed + left
+	e.Remaining = left
+}
+
+// sync folds the running segment up to now into elapsed and refreshes
+// Remaining.
+func (e *Engine) sync(now time.Time) {
+	e.elapsed += now.Sub(e.startedAt)
+	e.startedAt = now
+	e.Remaining = e.currentDuration() - e.elapsed
+}
+
+// fitOver reports whether a FitUntil plan is over: the deadline has passed
+// and no session is under way.
+func (e *Engine) fitOver(now time.Time) bool {
+	return !e.FitUntil.IsZero() && e.State == StateIdle && !now.Before(e.FitUntil)
+}
+
+// refit replaces the steps from the current one on with a plan that ends
+// at FitUntil, counting the time already run in the current session. A
+// break is kept if work still fits after it; otherwise the current session
+// is cut to end at the deadline, or now if it ran past it while paused, and
+// becomes the last. A plan that is over is left alone with nothing
+// remaining.
+func (e *Engine) refit(now time.Time) {
+	if e.fitOver(now) {
+		e.Remaining = 0
+		return
+	}
+	from := now.Add(-e.elapsed)
+	cur := e.Sequence[e.Step]
+	var tail []Step
+	if cur.Mode == ModeWork {
+		tail = planUntil(from, e.FitUntil, e.WorkDuration, e.ShortBreak, e.LongBreak, e.CyclesBeforeLong, e.Cycle)
+	} else if rest := planUntil(from.Add(cur.Duration), e.FitUntil, e.WorkDuration, e.ShortBreak, e.LongBreak, e.CyclesBeforeLong, e.Cycle); len(rest) > 0 {
+		tail = append([]Step{cur}, rest...)
+	}
+	if len(tail) == 0 {
+		cur.Duration = max(e.FitUntil.Sub(from), e.elapsed)
+		if cur.Mode == ModeWork {
+			cur.Label = "Final"
+		}
+		tail = []Step{cur}
+	}
+	e.Sequence = append(e.Sequence[:e.Step:e.Step], tail...)
+	e.Remaining = e.Sequence[e.Step].Duration - e.elapsed
+}
+
+// advance ends the current session and moves to the next one. It returns
+// the event describing the session that ended; as, unless EventNone,
+// replaces the type of a work or break completion. Follow-up events such as
+// EventLongBreakReached are emitted after it.
+func (e *Engine) advance(as EventType) Event {
+	if e.Kind == KindStopwatch {
+		return Event{Type: EventNone}
+	}
+	if e.State == StateRunning {
+		e.sync(e.clock.Now())
+	}
+	e.LastElapsed = e.elapsed
+	if d := e.currentDuration(); !e.CountsUp() && e.LastElapsed > d {
+		e.LastElapsed = d
+	}
+
+	ended := e.event(EventNone)
+	ended.Elapsed = e.LastElapsed
+	if e.Remaining < 0 && !e.CountsUp() {
+		// The deadline passed without a tick, e.g. while the machine slept:
+		// the session ended at the deadline, not now.
+		ended.At = ended.At.Add(e.Remaining)
+	}
+	wasLongBreak := e.Mode == ModeLongBreak
+
+	switch {
+	case e.Kind == KindCountdown:
+		ended.Type = EventFinished
+		e.Remaining = e.WorkDuration
+	case e.Kind == KindFlowtime:
+		if e.Mode == ModeWork {
+			e.Cycle++
+			ended.Type = EventWorkDone
+			e.flowBreak = e.FlowPolicy.Break(e.LastElapsed)
+			e.Mode = ModeShortBreak
+			e.Remaining = e.flowBreak
+		} else {
+			ended.Type = EventBreakDone
+			e.Mode = ModeWork
+			e.Remaining = 0
+		}
+	case len(e.Sequence) > 0:
+		if e.Mode == ModeWork {
+			e.Cycle++
+			ended.Type = EventWorkDone
+		} else {
+			ended.Type = EventBreakDone
+		}
+		if e.Once && e.Step == len(e.Sequence)-1 {
+			ended.Type = EventFinished
+		}
+		e.Step = (e.Step + 1) % len(e.Sequence)
+		e.Mode = e.Sequence[e.Step].Mode
+		e.Remaining = e.Sequence[e.Step].Duration
+	case e.Mode == ModeWork:
 		e.Cycle++
-		evt = EventWorkDone
+		ended.Type = EventWorkDone
 		if e.CyclesBeforeLong > 0 && e.Cycle%e.CyclesBeforeLong == 0 {
 			e.Mode = ModeLongBreak
 			e.Remaining = e.LongBreak
@@ -174,12 +795,49 @@ func (e *Engine) advance() Event {
 			e.Mode = ModeShortBreak
 			e.Remaining = e.ShortBreak
 		}
-	case ModeShortBreak, ModeLongBreak:
-		evt = EventBreakDone
+	default:
+		ended.Type = EventBreakDone
 		e.Mode = ModeWork
 		e.Remaining = e.WorkDuration
 	}
 
+	finished := ended.Type == EventFinished
+	completedRound := wasLongBreak && ended.Type == EventBreakDone && as == EventNone
+	if as != EventNone && !finished {
+		ended.Type = as
+	}
+	ended.Cycle = e.Cycle
+
 	e.State = StateIdle
-	return evt
+	e.elapsed = 0
+	e.autoStartAt = time.Time{}
+	e.sessionStart = time.Time{}
+	e.warned = false
+	e.Interruptions = nil
+	if !finished {
+		ended.Next = Step{Mode: e.Mode, Duration: e.currentDuration(), Label: e.Label()}
+	}
+
+	e.emit(ended)
+	if completedRound {
+		e.emit(e.event(EventCycleComplete))
+	}
+	now := e.clock.Now()
+	if finished {
+		e.retarget(now)
+		return ended
+	}
+	if e.Mode == ModeLongBreak {
+		e.emit(e.event(EventLongBreakReached))
+	}
+
+	if ((e.Mode == ModeWork && e.AutoStartWork) || (e.Mode != ModeWork && e.AutoStartBreaks)) && !e.fitOver(now) {
+		if e.AutoStartDelay > 0 {
+			e.autoStartAt = now.Add(e.AutoStartDelay)
+		} else {
+			e.start(now)
+			e.emit(e.event(EventStarted))
+		}
+	}
+	return ended
 }
@@ -3,10 +3,23 @@ package timer
 import (
 	"testing"
 	"time"
+
+	"github.com/and1truong/tui-timer/internal/clock/clocktest"
 )
 
+func newTestEngine(work, shortBreak, longBreak time.Duration, cyclesBeforeLong int) (*Engine, *clocktest.Fake) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	return New(work, shortBreak, longBreak, cyclesBeforeLong, clk), clk
+}
+
+// step advances the clock by one second and ticks.
+func step(e *Engine, clk *clocktest.Fake) Event {
+	clk.Advance(time.Second)
+	return e.Tick()
+}
+
 func TestNewEngine(t *testing.T) {
-	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
 	if e.Mode != ModeWork {
 		t.Errorf("expected ModeWork, got %v", e.Mode)
 	}
@@ -19,11 +32,11 @@ func TestNewEngine(t *testing.T) {
 }
 
 func TestToggle(t *testing.T) {
-	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
 
 	evt := e.Toggle()
-	if evt != EventStarted {
-		t.Errorf("expected EventStarted, got %v", evt)
+	if evt.Type != EventStarted {
+		t.Errorf("expected EventStarted, got %v", evt.Type)
 	}
 	if e.State != StateRunning {
 		t.Errorf("expected StateRunning, got %v", e.State)
@@ -41,12 +54,12 @@ func TestToggle(t *testing.T) {
 }
 
 func TestTickCountdown(t *testing.T) {
-	e := New(3*time.Second, 1*time.Second, 1*time.Second, 4)
+	e, clk := newTestEngine(3*time.Second, 1*time.Second, 1*time.Second, 4)
 	e.Toggle() // start
 
-	evt := e.Tick()
-	if evt != EventTick {
-		t.Errorf("expected EventTick, got %v", evt)
+	evt := step(e, clk)
+	if evt.Type != EventTick {
+		t.Errorf("expected EventTick, got %v", evt.Type)
 	}
 	if e.Remaining != 2*time.Second {
 		t.Errorf("expected 2s, got %v", e.Remaining)
@@ -54,22 +67,22 @@ func TestTickCountdown(t *testing.T) {
 }
 
 func TestTickDoesNothingWhenIdle(t *testing.T) {
-	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
 	evt := e.Tick()
-	if evt != EventNone {
-		t.Errorf("expected EventNone when idle, got %v", evt)
+	if evt.Type != EventNone {
+		t.Errorf("expected EventNone when idle, got %v", evt.Type)
 	}
 }
 
 func TestWorkToBreakTransition(t *testing.T) {
-	e := New(2*time.Second, 5*time.Minute, 15*time.Minute, 4)
+	e, clk := newTestEngine(2*time.Second, 5*time.Minute, 15*time.Minute, 4)
 	e.Toggle()
 
-	e.Tick() // 1s left
-	evt := e.Tick() // 0s -> transition
+	step(e, clk)        // 1s left
+	evt := step(e, clk) // 0s -> transition
 
-	if evt != EventWorkDone {
-		t.Errorf("expected EventWorkDone, got %v", evt)
+	if evt.Type != EventWorkDone {
+		t.Errorf("expected EventWorkDone, got %v", evt.Type)
 	}
 	if e.Mode != ModeShortBreak {
 		t.Errorf("expected ModeShortBreak, got %v", e.Mode)
@@ -83,17 +96,17 @@ func TestWorkToBreakTransition(t *testing.T) {
 }
 
 func TestLongBreakAfterCycles(t *testing.T) {
-	e := New(1*time.Second, 1*time.Second, 15*time.Minute, 2)
+	e, clk := newTestEngine(1*time.Second, 1*time.Second, 15*time.Minute, 2)
 
 	// Complete 2 work cycles
 	for i := 0; i < 2; i++ {
 		e.Toggle()
-		e.Tick() // work done
+		step(e, clk) // work done
 
 		if i < 1 {
 			// First cycle -> short break
 			e.Toggle()
-			e.Tick() // break done
+			step(e, clk) // break done
 		}
 	}
 
@@ -103,9 +116,9 @@ func TestLongBreakAfterCycles(t *testing.T) {
 }
 
 func TestReset(t *testing.T) {
-	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
 	e.Toggle()
-	e.Tick()
+	step(e, clk)
 	e.Reset()
 
 	if e.State != StateIdle {
@@ -117,26 +130,74 @@ func TestReset(t *testing.T) {
 }
 
 func TestSkip(t *testing.T) {
-	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
+	if evt := e.Skip(); evt.Type != EventNone || e.Mode != ModeWork {
+		t.Errorf("expected work not to be skippable, got %v/%v", evt.Type, e.Mode)
+	}
+
+	e.Toggle()
+	e.Finish()
 	evt := e.Skip()
+	if evt.Type != EventSkipped || evt.Mode != ModeShortBreak {
+		t.Errorf("expected EventSkipped for the break, got %v/%v", evt.Type, evt.Mode)
+	}
+	if e.Mode != ModeWork || e.Cycle != 1 {
+		t.Errorf("expected work after skipping the break, got %v (cycle %d)", e.Mode, e.Cycle)
+	}
+}
 
-	if evt != EventWorkDone {
-		t.Errorf("expected EventWorkDone on skip, got %v", evt)
+func TestFinishEarly(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	if evt := e.Finish(); evt.Type != EventNone || e.Cycle != 0 {
+		t.Errorf("expected nothing to finish before starting, got %v/cycle %d", evt.Type, e.Cycle)
 	}
-	if e.Mode != ModeShortBreak {
-		t.Errorf("expected ModeShortBreak after skip, got %v", e.Mode)
+
+	e.Toggle()
+	clk.Advance(20 * time.Minute)
+
+	evt := e.Finish()
+	if evt.Type != EventCompletedEarly || evt.Elapsed != 20*time.Minute || evt.Planned != 25*time.Minute {
+		t.Errorf("unexpected early completion %v/%v/%v", evt.Type, evt.Elapsed, evt.Planned)
+	}
+	if e.Cycle != 1 || e.Mode != ModeShortBreak {
+		t.Errorf("expected early completion to count, got cycle %d/%v", e.Cycle, e.Mode)
+	}
+	if evt := e.Finish(); evt.Type != EventNone {
+		t.Errorf("expected breaks not to be finishable, got %v", evt.Type)
+	}
+}
+
+func TestVoid(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	if evt := e.Void("bored"); evt.Type != EventNone {
+		t.Errorf("expected nothing to void before starting, got %v", evt.Type)
+	}
+
+	e.Toggle()
+	clk.Advance(12 * time.Minute)
+	e.Interrupt(InterruptExternal, "fire alarm")
+	evt := e.Void("fire alarm")
+
+	if evt.Type != EventVoided || evt.Reason != "fire alarm" || evt.Elapsed != 12*time.Minute {
+		t.Errorf("unexpected void event %v/%q/%v", evt.Type, evt.Reason, evt.Elapsed)
+	}
+	if len(evt.Interruptions) != 1 {
+		t.Errorf("expected void event to carry interruptions, got %d", len(evt.Interruptions))
+	}
+	if e.Cycle != 0 || e.Mode != ModeWork || e.State != StateIdle || e.Remaining != 25*time.Minute {
+		t.Errorf("expected a fresh uncounted work session, got cycle %d %v/%v/%v", e.Cycle, e.Mode, e.State, e.Remaining)
 	}
 }
 
 func TestProgress(t *testing.T) {
-	e := New(4*time.Second, 1*time.Second, 1*time.Second, 4)
+	e, clk := newTestEngine(4*time.Second, 1*time.Second, 1*time.Second, 4)
 	if e.Progress() != 0 {
 		t.Errorf("expected 0 progress at start")
 	}
 
 	e.Toggle()
-	e.Tick()
-	e.Tick()
+	step(e, clk)
+	step(e, clk)
 
 	got := e.Progress()
 	want := 0.5
@@ -146,16 +207,774 @@ func TestProgress(t *testing.T) {
 }
 
 func TestBreakToWorkTransition(t *testing.T) {
-	e := New(25*time.Minute, 1*time.Second, 15*time.Minute, 4)
-	e.Skip() // -> short break
+	e, clk := newTestEngine(25*time.Minute, 1*time.Second, 15*time.Minute, 4)
+	e.Toggle()
+	e.Finish() // -> short break
 
 	e.Toggle()
-	evt := e.Tick() // break done
+	evt := step(e, clk) // break done
 
-	if evt != EventBreakDone {
-		t.Errorf("expected EventBreakDone, got %v", evt)
+	if evt.Type != EventBreakDone {
+		t.Errorf("expected EventBreakDone, got %v", evt.Type)
 	}
 	if e.Mode != ModeWork {
 		t.Errorf("expected ModeWork after break, got %v", e.Mode)
 	}
 }
+
+func TestLateTicksDoNotDrift(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Toggle()
+
+	// Ticks arrive every 1.5s instead of every second.
+	for i := 0; i < 10; i++ {
+		clk.Advance(1500 * time.Millisecond)
+		e.Tick()
+	}
+
+	if want := 25*time.Minute - 15*time.Second; e.Remaining != want {
+		t.Errorf("expected %v remaining, got %v", want, e.Remaining)
+	}
+}
+
+func TestPauseExcludedFromElapsed(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	e.Toggle() // pause
+
+	clk.Advance(time.Hour)
+	if evt := e.Tick(); evt.Type != EventNone {
+		t.Errorf("expected EventNone while paused, got %v", evt.Type)
+	}
+
+	e.Toggle() // resume
+	clk.Advance(5 * time.Minute)
+	e.Tick()
+
+	if e.Elapsed() != 15*time.Minute {
+		t.Errorf("expected 15m elapsed, got %v", e.Elapsed())
+	}
+	if e.Remaining != 10*time.Minute {
+		t.Errorf("expected 10m remaining, got %v", e.Remaining)
+	}
+	deadline, ok := e.Deadline()
+	if !ok || !deadline.Equal(clk.Now().Add(10*time.Minute)) {
+		t.Errorf("unexpected deadline %v (ok=%v)", deadline, ok)
+	}
+}
+
+func TestSleepThroughDeadline(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Toggle()
+	deadline := clk.Now().Add(25 * time.Minute)
+
+	// Machine suspended for an hour with no ticks delivered.
+	clk.Advance(time.Hour)
+	evt := e.Tick()
+
+	if evt.Type != EventWorkDone {
+		t.Errorf("expected EventWorkDone on wake, got %v", evt.Type)
+	}
+	if !evt.At.Equal(deadline) || evt.Elapsed != 25*time.Minute {
+		t.Errorf("expected the session to end at its deadline %v after 25m, got %v after %v", deadline, evt.At, evt.Elapsed)
+	}
+	if e.Mode != ModeShortBreak || e.State != StateIdle {
+		t.Errorf("expected idle short break, got %v/%v", e.Mode, e.State)
+	}
+	if e.Remaining != 5*time.Minute {
+		t.Errorf("expected full break remaining, got %v", e.Remaining)
+	}
+
+	e.Toggle()
+	deadline = clk.Now().Add(5 * time.Minute)
+	clk.Advance(time.Hour)
+	if evt := e.Tick(); evt.Type != EventBreakDone || !evt.At.Equal(deadline) {
+		t.Errorf("expected EventBreakDone at %v on wake, got %v at %v", deadline, evt.Type, evt.At)
+	}
+}
+
+func TestAdjustTimeWhileRunning(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Toggle()
+	clk.Advance(5 * time.Minute)
+
+	e.AdjustTime(time.Minute)
+	clk.Advance(time.Minute)
+	e.Tick()
+
+	if e.Remaining != 20*time.Minute {
+		t.Errorf("expected 20m remaining, got %v", e.Remaining)
+	}
+}
+
+func TestFullDaySimulation(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	start := clk.Now()
+
+	var workDone, breakDone int
+	for workDone < 4 || breakDone < 4 {
+		if e.State == StateIdle {
+			e.Toggle()
+		}
+		switch step(e, clk).Type {
+		case EventWorkDone:
+			workDone++
+		case EventBreakDone:
+			breakDone++
+		}
+	}
+
+	if e.Cycle != 4 {
+		t.Errorf("expected 4 cycles, got %d", e.Cycle)
+	}
+	if e.Mode != ModeWork {
+		t.Errorf("expected ModeWork after the long break, got %v", e.Mode)
+	}
+	want := 4*25*time.Minute + 3*5*time.Minute + 15*time.Minute
+	if got := clk.Now().Sub(start); got != want {
+		t.Errorf("expected day to last %v, got %v", want, got)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Cycle = 2
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	snap := e.Snapshot()
+
+	// Process closed for 5 minutes.
+	clk.Advance(5 * time.Minute)
+	r := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+	r.Restore(snap)
+
+	if r.Cycle != 2 || r.Mode != ModeWork || r.State != StateRunning {
+		t.Errorf("unexpected restored state: cycle=%d mode=%v state=%v", r.Cycle, r.Mode, r.State)
+	}
+	if r.Remaining != 10*time.Minute {
+		t.Errorf("expected 10m remaining after restore, got %v", r.Remaining)
+	}
+
+	// Closed long enough for the session to expire.
+	clk.Advance(time.Hour)
+	r = New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+	r.Restore(snap)
+	if evt := r.Tick(); evt.Type != EventWorkDone {
+		t.Errorf("expected EventWorkDone after expired restore, got %v", evt.Type)
+	}
+	if r.Cycle != 3 {
+		t.Errorf("expected cycle 3, got %d", r.Cycle)
+	}
+}
+
+func TestRestorePausedIgnoresDowntime(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	e.Toggle()
+	snap := e.Snapshot()
+
+	clk.Advance(time.Hour)
+	r := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+	r.Restore(snap)
+
+	if r.State != StatePaused || r.Remaining != 15*time.Minute {
+		t.Errorf("expected paused with 15m remaining, got %v/%v", r.State, r.Remaining)
+	}
+}
+
+func TestAutoStartBreaks(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.AutoStartBreaks = true
+	e.AutoStartDelay = 5 * time.Second
+	e.Toggle()
+
+	clk.Advance(25 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventWorkDone {
+		t.Fatalf("expected EventWorkDone, got %v", evt.Type)
+	}
+	if in, ok := e.AutoStartIn(); !ok || in != 5*time.Second {
+		t.Fatalf("expected auto-start in 5s, got %v (ok=%v)", in, ok)
+	}
+
+	clk.Advance(4 * time.Second)
+	if evt := e.Tick(); evt.Type != EventNone || e.State != StateIdle {
+		t.Fatalf("expected to still be waiting, got %v/%v", evt.Type, e.State)
+	}
+	clk.Advance(time.Second)
+	if evt := e.Tick(); evt.Type != EventStarted || e.State != StateRunning {
+		t.Fatalf("expected break to start, got %v/%v", evt.Type, e.State)
+	}
+
+	// Work does not auto-start.
+	clk.Advance(5 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventBreakDone {
+		t.Fatalf("expected EventBreakDone, got %v", evt.Type)
+	}
+	if _, ok := e.AutoStartIn(); ok {
+		t.Error("expected no auto-start for work")
+	}
+}
+
+func TestCancelAutoStart(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.AutoStartWork = true
+	e.AutoStartDelay = 5 * time.Second
+	e.Toggle()
+	e.Finish() // -> short break
+	e.Skip()   // -> work, auto-start pending
+
+	if !e.CancelAutoStart() {
+		t.Fatal("expected a pending auto-start")
+	}
+	clk.Advance(time.Minute)
+	if evt := e.Tick(); evt.Type != EventNone || e.State != StateIdle {
+		t.Errorf("expected idle after cancel, got %v/%v", evt.Type, e.State)
+	}
+}
+
+func TestAutoStartWithoutDelay(t *testing.T) {
+	e, _ := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	e.AutoStartBreaks = true
+	e.Toggle()
+	e.Finish()
+
+	if e.State != StateRunning {
+		t.Errorf("expected break to start immediately, got %v", e.State)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := NewSequence([]Step{
+		{Mode: ModeWork, Duration: 10 * time.Minute, Label: "Warm-up"},
+		{Mode: ModeWork, Duration: 50 * time.Minute},
+		{Mode: ModeLongBreak, Duration: 60 * time.Minute, Label: "Lunch"},
+	}, clk)
+
+	if e.Label() != "Warm-up" || e.Remaining != 10*time.Minute {
+		t.Fatalf("unexpected first step %q/%v", e.Label(), e.Remaining)
+	}
+
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventWorkDone {
+		t.Errorf("expected EventWorkDone, got %v", evt.Type)
+	}
+	if e.Step != 1 || e.Mode != ModeWork || e.Label() != "Work" {
+		t.Errorf("expected unlabelled work step, got %d/%v/%q", e.Step, e.Mode, e.Label())
+	}
+
+	e.Toggle()
+	e.Finish()
+	if e.Label() != "Lunch" || e.Remaining != time.Hour || e.Cycle != 2 {
+		t.Errorf("expected lunch after 2 cycles, got %q/%v/%d", e.Label(), e.Remaining, e.Cycle)
+	}
+
+	if evt := e.Skip(); evt.Type != EventSkipped {
+		t.Errorf("expected EventSkipped, got %v", evt.Type)
+	}
+	if e.Step != 0 {
+		t.Errorf("expected sequence to loop, got step %d", e.Step)
+	}
+}
+
+func TestSequenceAdjustTime(t *testing.T) {
+	e := NewSequence([]Step{
+		{Mode: ModeWork, Duration: 52 * time.Minute},
+		{Mode: ModeShortBreak, Duration: 17 * time.Minute},
+	}, nil)
+
+	e.AdjustTime(-10 * time.Minute)
+	if e.Sequence[0].Duration != 42*time.Minute || e.Remaining != 42*time.Minute {
+		t.Errorf("expected 42m, got %v/%v", e.Sequence[0].Duration, e.Remaining)
+	}
+}
+
+func TestFlowPolicyBreak(t *testing.T) {
+	ratio := FlowPolicy{Ratio: 0.2}
+	if got := ratio.Break(50 * time.Minute); got != 10*time.Minute {
+		t.Errorf("expected 10m break, got %v", got)
+	}
+
+	brackets := FlowPolicy{Brackets: []Bracket{
+		{UpTo: 25 * time.Minute, Break: 5 * time.Minute},
+		{UpTo: 50 * time.Minute, Break: 8 * time.Minute},
+		{UpTo: 90 * time.Minute, Break: 10 * time.Minute},
+	}}
+	for work, want := range map[time.Duration]time.Duration{
+		10 * time.Minute:  5 * time.Minute,
+		50 * time.Minute:  8 * time.Minute,
+		120 * time.Minute: 10 * time.Minute,
+	} {
+		if got := brackets.Break(work); got != want {
+			t.Errorf("Break(%v): expected %v, got %v", work, want, got)
+		}
+	}
+}
+
+func TestFlowtime(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := NewFlowtime(FlowPolicy{Ratio: 0.2}, clk)
+	e.Toggle()
+
+	// Work never completes on its own.
+	clk.Advance(3 * time.Hour)
+	if evt := e.Tick(); evt.Type != EventTick {
+		t.Fatalf("expected EventTick while counting up, got %v", evt.Type)
+	}
+	if !e.CountsUp() || e.Elapsed() != 3*time.Hour {
+		t.Fatalf("expected 3h counted up, got %v", e.Elapsed())
+	}
+	e.Toggle() // pause
+	clk.Advance(time.Hour)
+	e.Toggle()
+	clk.Advance(30 * time.Minute)
+
+	if evt := e.Finish(); evt.Type != EventWorkDone {
+		t.Fatalf("expected EventWorkDone, got %v", evt.Type)
+	}
+	if e.LastElapsed != 210*time.Minute {
+		t.Errorf("expected 3h30m of work, got %v", e.LastElapsed)
+	}
+	if e.Mode != ModeShortBreak || e.Remaining != 42*time.Minute {
+		t.Errorf("expected 42m break, got %v/%v", e.Mode, e.Remaining)
+	}
+
+	e.Toggle()
+	clk.Advance(42 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventBreakDone {
+		t.Errorf("expected EventBreakDone, got %v", evt.Type)
+	}
+	if !e.CountsUp() || e.Cycle != 1 {
+		t.Errorf("expected to be back counting up after cycle 1, got cycle %d", e.Cycle)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := NewCountdown(12*time.Minute, clk)
+	e.AutoStartWork = true
+	e.Toggle()
+
+	clk.Advance(12 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventFinished {
+		t.Fatalf("expected EventFinished, got %v", evt.Type)
+	}
+	if e.State != StateIdle || e.Remaining != 12*time.Minute || e.Cycle != 0 {
+		t.Errorf("expected idle full countdown with no cycle, got %v/%v/%d", e.State, e.Remaining, e.Cycle)
+	}
+	if _, ok := e.AutoStartIn(); ok {
+		t.Error("countdown must not auto-start")
+	}
+}
+
+func TestStopwatchLaps(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := NewStopwatch(clk)
+
+	if _, ok := e.Lap(); ok {
+		t.Error("expected no lap while idle")
+	}
+	e.Toggle()
+	clk.Advance(90 * time.Second)
+	e.Lap()
+	clk.Advance(30 * time.Second)
+	e.Toggle() // pause
+	clk.Advance(time.Hour)
+	e.Toggle()
+	clk.Advance(15 * time.Second)
+	lap, _ := e.Lap()
+
+	if lap != 45*time.Second {
+		t.Errorf("expected 45s lap, got %v", lap)
+	}
+	if len(e.Laps) != 2 || e.Laps[0] != 90*time.Second {
+		t.Errorf("unexpected laps %v", e.Laps)
+	}
+	if evt := e.Skip(); evt.Type != EventNone {
+		t.Errorf("expected skip to do nothing, got %v", evt.Type)
+	}
+
+	e.Reset()
+	if len(e.Laps) != 0 || e.Elapsed() != 0 {
+		t.Errorf("expected reset to clear laps, got %v/%v", e.Laps, e.Elapsed())
+	}
+}
+
+func TestRestoreIgnoresOtherKind(t *testing.T) {
+	e := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, nil)
+	e.Cycle = 3
+	f := NewFlowtime(FlowPolicy{Ratio: 0.2}, nil)
+
+	if f.Restore(e.Snapshot()) {
+		t.Error("expected a pomodoro snapshot to be ignored by flowtime")
+	}
+	if f.Cycle != 0 {
+		t.Errorf("expected cycle 0, got %d", f.Cycle)
+	}
+}
+
+func TestUntil(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC))
+	e := NewUntil(time.Date(2024, 1, 1, 17, 30, 0, 0, time.UTC), clk)
+
+	if e.Remaining != 90*time.Minute || e.Label() != "Until 17:30" {
+		t.Fatalf("unexpected initial state %v/%q", e.Remaining, e.Label())
+	}
+
+	// Idle time still counts towards the deadline.
+	clk.Advance(10 * time.Minute)
+	e.Tick()
+	if e.Remaining != 80*time.Minute {
+		t.Errorf("expected 80m while idle, got %v", e.Remaining)
+	}
+
+	e.Toggle()
+	clk.Advance(20 * time.Minute)
+	e.Toggle() // pause does not move the deadline
+	clk.Advance(20 * time.Minute)
+	e.Toggle()
+	e.Tick()
+	if e.Remaining != 40*time.Minute {
+		t.Errorf("expected 40m after pause, got %v", e.Remaining)
+	}
+
+	clk.Advance(40 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventFinished {
+		t.Errorf("expected EventFinished at the deadline, got %v", evt.Type)
+	}
+}
+
+func TestPlanUntil(t *testing.T) {
+	from := time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC)
+	until := from.Add(2*time.Hour + 30*time.Minute)
+	steps := PlanUntil(from, until, 25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+
+	var total time.Duration
+	var work int
+	for _, s := range steps {
+		total += s.Duration
+		if s.Mode == ModeWork {
+			work++
+		}
+	}
+	if total != 150*time.Minute {
+		t.Errorf("expected plan to fill 2h30m, got %v", total)
+	}
+	if work != 5 {
+		t.Errorf("expected 5 work sessions, got %d", work)
+	}
+	if steps[7].Mode != ModeLongBreak {
+		t.Errorf("expected a long break after 4 cycles, got %v", steps[7].Mode)
+	}
+	last := steps[len(steps)-1]
+	if last.Mode != ModeWork || last.Duration != 20*time.Minute || last.Label != "Final" {
+		t.Errorf("expected a shortened final work session, got %+v", last)
+	}
+}
+
+func TestOnceSequenceFinishes(t *testing.T) {
+	e := NewSequence([]Step{
+		{Mode: ModeWork, Duration: 25 * time.Minute},
+		{Mode: ModeShortBreak, Duration: 5 * time.Minute},
+		{Mode: ModeWork, Duration: 10 * time.Minute},
+	}, nil)
+	e.Once = true
+	e.AutoStartWork = true
+
+	e.Toggle()
+	e.Finish()
+	e.Skip()
+	if evt := e.Finish(); evt.Type != EventFinished {
+		t.Errorf("expected EventFinished after the last step, got %v", evt.Type)
+	}
+	if e.Cycle != 2 || e.State != StateIdle {
+		t.Errorf("expected 2 idle cycles, got %d/%v", e.Cycle, e.State)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 1)
+
+	var got []EventType
+	unsubscribe := e.Subscribe(func(evt Event) {
+		if evt.Type != EventTick {
+			got = append(got, evt.Type)
+		}
+	})
+
+	e.Toggle()
+	clk.Advance(time.Minute)
+	e.Toggle()
+	e.Toggle()
+	e.AdjustTime(time.Minute)
+	e.Reset()
+	e.Toggle()
+	clk.Advance(26 * time.Minute)
+	e.Tick()
+	e.Toggle()
+	clk.Advance(15 * time.Minute)
+	e.Tick()
+	e.Toggle()
+	e.Void("meeting")
+	e.Toggle()
+	e.Finish()
+	e.Skip()
+
+	want := []EventType{
+		EventStarted, EventPaused, EventResumed, EventAdjusted, EventReset,
+		EventStarted, EventWorkDone, EventLongBreakReached,
+		EventStarted, EventBreakDone, EventCycleComplete,
+		EventStarted, EventVoided, EventStarted, EventCompletedEarly, EventLongBreakReached,
+		EventSkipped,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+
+	unsubscribe()
+	e.Reset()
+	if len(got) != len(want) {
+		t.Error("expected no events after unsubscribe")
+	}
+}
+
+func TestEventPayload(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+	start := clk.Now()
+
+	var done Event
+	e.Subscribe(func(evt Event) {
+		if evt.Type == EventWorkDone {
+			done = evt
+		}
+	})
+
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	e.Toggle()
+	clk.Advance(time.Hour)
+	e.Toggle()
+	clk.Advance(15 * time.Minute)
+	e.Tick()
+
+	if done.Mode != ModeWork || done.Cycle != 1 {
+		t.Errorf("unexpected mode/cycle %v/%d", done.Mode, done.Cycle)
+	}
+	if done.Planned != 25*time.Minute || done.Elapsed != 25*time.Minute {
+		t.Errorf("unexpected planned/elapsed %v/%v", done.Planned, done.Elapsed)
+	}
+	if !done.Start.Equal(start) || !done.At.Equal(clk.Now()) {
+		t.Errorf("unexpected start/at %v/%v", done.Start, done.At)
+	}
+}
+
+func TestInterruptions(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+
+	if evt := e.Interrupt(InterruptInternal, ""); evt.Type != EventNone {
+		t.Errorf("expected no interruption before starting, got %v", evt.Type)
+	}
+
+	e.Toggle()
+	clk.Advance(5 * time.Minute)
+	e.Interrupt(InterruptInternal, "check email")
+	e.Interrupt(InterruptExternal, "phone")
+	evt := e.Interrupt(InterruptExternal, "")
+
+	if evt.Type != EventInterrupted || len(evt.Interruptions) != 3 {
+		t.Fatalf("expected third interruption event, got %v/%d", evt.Type, len(evt.Interruptions))
+	}
+	if in := evt.Interruptions[0]; in.Note != "check email" || !in.At.Equal(clk.Now()) {
+		t.Errorf("unexpected first interruption %+v", in)
+	}
+	if internal, external := e.InterruptionCounts(); internal != 1 || external != 2 {
+		t.Errorf("expected 1 internal and 2 external, got %d/%d", internal, external)
+	}
+
+	clk.Advance(20 * time.Minute)
+	done := e.Tick()
+	if len(done.Interruptions) != 3 {
+		t.Errorf("expected work-done to carry 3 interruptions, got %d", len(done.Interruptions))
+	}
+	if len(e.Interruptions) != 0 {
+		t.Error("expected interruptions to reset for the break")
+	}
+
+	e.Toggle()
+	if evt := e.Interrupt(InterruptInternal, ""); evt.Type != EventNone {
+		t.Errorf("expected no interruption during a break, got %v", evt.Type)
+	}
+}
+
+func TestSetTask(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, 5*time.Minute, 15*time.Minute, 4)
+
+	evt := e.SetTask("  Invoicing ")
+	if evt.Type != EventTaskChanged || evt.Task != "Invoicing" || evt.PrevTask != "" {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+	if evt := e.SetTask("Invoicing"); evt.Type != EventNone {
+		t.Errorf("expected no event for the same task, got %v", evt.Type)
+	}
+
+	e.Toggle()
+	clk.Advance(25 * time.Minute)
+	if done := e.Tick(); done.Task != "Invoicing" {
+		t.Errorf("expected work-done to carry the task, got %q", done.Task)
+	}
+	if e.Task != "Invoicing" {
+		t.Error("expected the task to carry over to the next session")
+	}
+
+	r := New(25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+	r.Restore(e.Snapshot())
+	if r.Task != "Invoicing" {
+		t.Errorf("expected restored task, got %q", r.Task)
+	}
+
+	if evt := e.SetTask(""); evt.Type != EventTaskChanged || evt.PrevTask != "Invoicing" {
+		t.Errorf("expected clearing to report the previous task, got %+v", evt)
+	}
+}
+
+func TestWarning(t *testing.T) {
+	e, clk := newTestEngine(25*time.Minute, time.Minute, 15*time.Minute, 4)
+	e.WarnBefore = 2 * time.Minute
+
+	var warnings int
+	e.Subscribe(func(evt Event) {
+		if evt.Type == EventWarning {
+			warnings++
+		}
+	})
+
+	e.Toggle()
+	clk.Advance(22 * time.Minute)
+	e.Tick()
+	if warnings != 0 {
+		t.Fatalf("expected no warning with 3m left, got %d", warnings)
+	}
+	clk.Advance(time.Minute)
+	e.Tick()
+	step(e, clk)
+	if warnings != 1 {
+		t.Fatalf("expected one warning with 2m left, got %d", warnings)
+	}
+
+	e.AdjustTime(5 * time.Minute)
+	step(e, clk)
+	clk.Advance(5 * time.Minute)
+	e.Tick()
+	if warnings != 2 {
+		t.Errorf("expected added time to re-arm the warning, got %d", warnings)
+	}
+
+	clk.Advance(2 * time.Minute)
+	done := e.Tick()
+	if done.Next.Mode != ModeShortBreak || done.Next.Duration != time.Minute {
+		t.Errorf("expected the next session to be a 1m short break, got %+v", done.Next)
+	}
+	e.Toggle()
+	clk.Advance(30 * time.Second)
+	e.Tick()
+	if warnings != 2 {
+		t.Errorf("expected no warning for a session shorter than WarnBefore, got %d", warnings)
+	}
+}
+
+func TestFitUntilLateStart(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	until := clk.Now().Add(time.Hour)
+	e := NewFitUntil(until, 25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+
+	var finished time.Time
+	e.Subscribe(func(evt Event) {
+		if evt.Type == EventFinished {
+			finished = evt.At
+		}
+	})
+
+	// Start 10 minutes late, pause for 3 minutes in the first session and
+	// start the break and the next session by hand a minute late each.
+	clk.Advance(10 * time.Minute)
+	e.Toggle()
+	clk.Advance(10 * time.Minute)
+	e.Toggle()
+	clk.Advance(3 * time.Minute)
+	e.Tick()
+	e.Toggle()
+	for i := 0; i < 60 && finished.IsZero(); i++ {
+		clk.Advance(time.Minute)
+		if evt := e.Tick(); evt.Type == EventWorkDone || evt.Type == EventBreakDone {
+			clk.Advance(time.Minute)
+			e.Tick()
+			e.Toggle()
+		}
+	}
+
+	if !finished.Equal(until) {
+		t.Errorf("expected the plan to finish at %v, finished at %v", until.Format("15:04"), finished.Format("15:04"))
+	}
+	if e.Cycle != 2 {
+		t.Errorf("expected 2 work sessions, got %d", e.Cycle)
+	}
+
+	if e := NewFitUntil(clk.Now().Add(30*time.Second), 25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk); e.Kind != KindCountdown {
+		t.Error("expected a plain countdown when no work fits")
+	}
+}
+
+func TestFitUntilStaysFinished(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	until := clk.Now().Add(30 * time.Minute)
+	e := NewFitUntil(until, 25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+	e.AutoStartWork = true
+
+	var events []EventType
+	e.Subscribe(func(evt Event) { events = append(events, evt.Type) })
+
+	e.Toggle()
+	clk.Advance(30 * time.Minute)
+	if evt := e.Tick(); evt.Type != EventFinished {
+		t.Fatalf("expected the plan to finish, got %v", evt.Type)
+	}
+	events = nil
+
+	for i := 0; i < 3; i++ {
+		clk.Advance(time.Second)
+		if evt := e.Tick(); evt.Type != EventNone {
+			t.Errorf("expected no event on an idle tick, got %v", evt.Type)
+		}
+		if evt := e.Toggle(); evt.Type != EventNone {
+			t.Errorf("expected start to do nothing once the plan is over, got %v", evt.Type)
+		}
+	}
+	if len(events) != 0 || e.State != StateIdle || e.Remaining != 0 {
+		t.Errorf("expected the plan to stay finished, got %v, state %v, %v left", events, e.State, e.Remaining)
+	}
+	if e.Cycle != 1 {
+		t.Errorf("expected 1 work session, got %d", e.Cycle)
+	}
+}
+
+func TestFitUntilIgnoresAdjustTime(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e := NewFitUntil(clk.Now().Add(time.Hour), 25*time.Minute, 5*time.Minute, 15*time.Minute, 4, clk)
+
+	if evt := e.AdjustTime(time.Minute); evt.Type != EventNone {
+		t.Errorf("expected EventNone, got %v", evt.Type)
+	}
+	clk.Advance(time.Second)
+	e.Tick()
+	if e.Remaining != 25*time.Minute {
+		t.Errorf("expected 25m, got %v", e.Remaining)
+	}
+}
@@ -0,0 +1,162 @@
+package timer
+
+import "time"
+
+// EventType identifies what happened to the engine.
+type EventType int
+
+const (
+	EventNone EventType = iota
+	EventTick
+	EventWorkDone  // a work session completed
+	EventBreakDone // a break completed
+	EventStarted
+	EventFinished // a standalone countdown or one-shot sequence ended
+	EventPaused
+	EventResumed
+	EventReset
+	EventSkipped  // a break was skipped
+	EventAdjusted // the session length changed by Event.Delta
+	EventLap      // a stopwatch lap of length Event.Delta was recorded
+	// EventCycleComplete follows the EventBreakDone of a long break, marking
+	// the end of a full round of pomodoros.
+	EventCycleComplete
+	// EventLongBreakReached is emitted when the next session is a long break.
+	EventLongBreakReached
+	// EventInterrupted is emitted when an interruption is recorded; it is the
+	// last entry of Event.Interruptions.
+	EventInterrupted
+	// EventCompletedEarly is a work session ended before its time that still
+	// counts as a completed cycle.
+	EventCompletedEarly
+	// EventVoided is a work session abandoned without counting, for
+	// Event.Reason.
+	EventVoided
+	// EventTaskChanged is emitted when Event.Task replaces Event.PrevTask.
+	EventTaskChanged
+	// EventWarning is emitted when the running session has Engine.WarnBefore
+	// left.
+	EventWarning
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventNone:
+		return "none"
+	case EventTick:
+		return "tick"
+	case EventWorkDone:
+		return "work-done"
+	case EventBreakDone:
+		return "break-done"
+	case EventStarted:
+		return "started"
+	case EventFinished:
+		return "finished"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	case EventReset:
+		return "reset"
+	case EventSkipped:
+		return "skipped"
+	case EventAdjusted:
+		return "adjusted"
+	case EventLap:
+		return "lap"
+	case EventCycleComplete:
+		return "cycle-complete"
+	case EventLongBreakReached:
+		return "long-break-reached"
+	case EventInterrupted:
+		return "interrupted"
+	case EventCompletedEarly:
+		return "completed-early"
+	case EventVoided:
+		return "voided"
+	case EventTaskChanged:
+		return "task-changed"
+	case EventWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened to the engine. Mode, Label,
+// Planned, Elapsed and Start describe the session the event is about: for
+// completions and skips that is the session that just ended, otherwise the
+// current one.
+type Event struct {
+	Type     EventType
+	Kind     Kind
+	Mode     Mode
+	Label    string
+	Cycle    int           // completed work cycles after the event
+	Planned  time.Duration // planned session length, zero when counting up
+	Elapsed  time.Duration // time run in the session, excluding pauses
+	Delta    time.Duration // adjustment or lap length
+	Reason   string        // why a session was voided
+	Task     string        // what the session is spent on
+	PrevTask string        // the task replaced, for EventTaskChanged
+	Start    time.Time     // when the session was first started, if it was
+	At       time.Time
+
+	// Next is the session that follows, for events that end a session
+	// other than EventFinished.
+	Next Step
+
+	Interruptions []Interruption // recorded during the session
+}
+
+// Handler receives engine events. Handlers run synchronously on the
+// goroutine driving the engine and must not call back into it.
+type Handler func(Event)
+
+type subscriber struct {
+	id int
+	fn Handler
+}
+
+// Subscribe registers h for every event the engine emits, including ticks.
+// The returned function removes the subscription.
+func (e *Engine) Subscribe(h Handler) (unsubscribe func()) {
+	e.nextID++
+	id := e.nextID
+	e.subs = append(e.subs, subscriber{id: id, fn: h})
+	return func() {
+		for i, s := range e.subs {
+			if s.id == id {
+				e.subs = append(e.subs[:i:i], e.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// event builds an event of type t describing the current session.
+func (e *Engine) event(t EventType) Event {
+	return Event{
+		Type:    t,
+		Kind:    e.Kind,
+		Mode:    e.Mode,
+		Label:   e.Label(),
+		Cycle:   e.Cycle,
+		Planned: e.currentDuration(),
+		Elapsed: e.Elapsed(),
+		Task:    e.Task,
+		Start:   e.sessionStart,
+		At:      e.clock.Now(),
+
+		Interruptions: e.Interruptions,
+	}
+}
+
+// emit delivers evt to subscribers and returns it.
+func (e *Engine) emit(evt Event) Event {
+	for _, s := range e.subs {
+		s.fn(evt)
+	}
+	return evt
+}
@@ -0,0 +1,59 @@
+package timer
+
+import "time"
+
+// InterruptionKind classifies an interruption as in the Pomodoro Technique:
+// internal ones come from yourself, external ones from other people.
+type InterruptionKind int
+
+const (
+	InterruptInternal InterruptionKind = iota
+	InterruptExternal
+)
+
+func (k InterruptionKind) String() string {
+	if k == InterruptExternal {
+		return "external"
+	}
+	return "internal"
+}
+
+// Interruption is a distraction recorded during a work session.
+type Interruption struct {
+	Kind InterruptionKind `json:"kind"`
+	Note string           `json:"note,omitempty"`
+	At   time.Time        `json:"at"`
+}
+
+// Interrupt records an interruption of the current work session. It only
+// applies to a started work session and returns EventNone otherwise.
+func (e *Engine) Interrupt(kind InterruptionKind, note string) Event {
+	if !e.Interruptible() {
+		return Event{Type: EventNone}
+	}
+	e.Interruptions = append(e.Interruptions, Interruption{
+		Kind: kind,
+		Note: note,
+		At:   e.clock.Now(),
+	})
+	return e.emit(e.event(EventInterrupted))
+}
+
+// Interruptible reports whether Interrupt would record anything.
+func (e *Engine) Interruptible() bool {
+	return e.Mode == ModeWork && e.State != StateIdle &&
+		(e.Kind == KindPomodoro || e.Kind == KindFlowtime)
+}
+
+// InterruptionCounts returns the number of internal and external
+// interruptions in the current session.
+func (e *Engine) InterruptionCounts() (internal, external int) {
+	for _, in := range e.Interruptions {
+		if in.Kind == InterruptExternal {
+			external++
+		} else {
+			internal++
+		}
+	}
+	return internal, external
+}
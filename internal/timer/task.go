@@ -0,0 +1,17 @@
+package timer
+
+import "strings"
+
+// SetTask names what the current and following work sessions are spent on.
+// An empty name clears it. It returns EventNone when nothing changes.
+func (e *Engine) SetTask(task string) Event {
+	task = strings.TrimSpace(task)
+	if task == e.Task {
+		return Event{Type: EventNone}
+	}
+	prev := e.Task
+	e.Task = task
+	evt := e.event(EventTaskChanged)
+	evt.PrevTask = prev
+	return e.emit(evt)
+}
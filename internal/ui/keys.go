@@ -3,15 +3,34 @@ package ui
 import "github.com/charmbracelet/bubbles/key"
 
 type keyMap struct {
-	Toggle    key.Binding
-	Reset     key.Binding
-	Skip      key.Binding
-	Quit      key.Binding
-	Config    key.Binding
-	TimeUp    key.Binding
-	TimeDown  key.Binding
-	TimeRight key.Binding
-	TimeLeft  key.Binding
+	Toggle   key.Binding
+	Reset    key.Binding
+	Skip     key.Binding
+	Finish   key.Binding
+	Void     key.Binding
+	Lap      key.Binding
+	Internal key.Binding
+	External key.Binding
+	Cancel   key.Binding
+	Quit     key.Binding
+	Config   key.Binding
+	Stats    key.Binding
+	Task     key.Binding
+	Tasks    key.Binding
+
+	// Task panel
+	TaskAdd      key.Binding
+	TaskSelect   key.Binding
+	TaskDone     key.Binding
+	TaskDelete   key.Binding
+	EstimateUp   key.Binding
+	EstimateDown key.Binding
+	MoveUp       key.Binding
+	MoveDown     key.Binding
+	TimeUp       key.Binding
+	TimeDown     key.Binding
+	TimeRight    key.Binding
+	TimeLeft     key.Binding
 }
 
 func newKeyMap() keyMap {
@@ -26,7 +45,31 @@ func newKeyMap() keyMap {
 		),
 		Skip: key.NewBinding(
 			key.WithKeys("s"),
-			key.WithHelp("s", "skip"),
+			key.WithHelp("s", "skip break"),
+		),
+		Finish: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "complete early"),
+		),
+		Void: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "void session"),
+		),
+		Lap: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "lap"),
+		),
+		Internal: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "internal interruption"),
+		),
+		External: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "external interruption"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel auto-start"),
 		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
@@ -36,6 +79,50 @@ func newKeyMap() keyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "config"),
 		),
+		Stats: key.NewBinding(
+			key.WithKeys("h"),
+			key.WithHelp("h", "stats"),
+		),
+		Task: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "set task"),
+		),
+		Tasks: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "task list"),
+		),
+		TaskAdd: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add task"),
+		),
+		TaskSelect: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "work on task"),
+		),
+		TaskDone: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "complete task"),
+		),
+		TaskDelete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete task"),
+		),
+		EstimateUp: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "estimate +1"),
+		),
+		EstimateDown: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "estimate -1"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("K", "shift+up"),
+			key.WithHelp("K", "move task up"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("J", "shift+down"),
+			key.WithHelp("J", "move task down"),
+		),
 		TimeUp: key.NewBinding(
 			key.WithKeys("shift+up"),
 			key.WithHelp("shift+↑", "+1 min"),
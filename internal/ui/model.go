@@ -1,16 +1,21 @@
 package ui
 
 import (
-	"context"
 	"os"
 	"os/exec"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/and1truong/tui-timer/internal/clock"
 	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/history"
 	"github.com/and1truong/tui-timer/internal/logger"
+	"github.com/and1truong/tui-timer/internal/notify"
 	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/state"
+	"github.com/and1truong/tui-timer/internal/tasks"
 	"github.com/and1truong/tui-timer/internal/timer"
 )
 
@@ -18,35 +23,138 @@ type tickMsg time.Time
 
 type Model struct {
 	engine *timer.Engine
+	clock  clock.Clock
 	keys   keyMap
 	cfg    *config.Config
-	player sound.Player
 	logger *logger.Logger
-	width  int
-	height int
+	state  *state.Store
+	prompt promptKind
+	input  textinput.Model
+	stats  *statsView
+	tasks  *taskPanel
+	// showStats and showTasks swap the timer for the statistics screen or
+	// the task list.
+	showStats bool
+	showTasks bool
+	width     int
+	height    int
 }
 
-func NewModel(cfg *config.Config, player sound.Player, log *logger.Logger) Model {
-	e := timer.New(cfg.WorkDuration, cfg.ShortBreak, cfg.LongBreak, cfg.CyclesBeforeLong)
+// NewModel creates the root model. A nil clk uses the system clock.
+func NewModel(cfg *config.Config, player sound.Player, log *logger.Logger, clk clock.Clock) Model {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	var e *timer.Engine
+	switch {
+	case cfg.Stopwatch:
+		e = timer.NewStopwatch(clk)
+	case cfg.Countdown > 0:
+		e = timer.NewCountdown(cfg.Countdown, clk)
+	case !cfg.Until.IsZero() && cfg.FitUntil:
+		e = timer.NewFitUntil(cfg.Until, cfg.WorkDuration, cfg.ShortBreak, cfg.LongBreak, cfg.CyclesBeforeLong, clk)
+	case !cfg.Until.IsZero():
+		e = timer.NewUntil(cfg.Until, clk)
+	case cfg.Flowtime.Enabled:
+		e = timer.NewFlowtime(cfg.FlowPolicy, clk)
+	case len(cfg.Steps) > 0:
+		e = timer.NewSequence(cfg.Steps, clk)
+	default:
+		e = timer.New(cfg.WorkDuration, cfg.ShortBreak, cfg.LongBreak, cfg.CyclesBeforeLong, clk)
+	}
+	e.AutoStartBreaks = cfg.AutoStartBreaks
+	e.AutoStartWork = cfg.AutoStartWork
+	e.AutoStartDelay = cfg.AutoStartDelay
+	e.Task = cfg.Task
+	e.WarnBefore = cfg.Sounds.WarningBefore
+
+	sv := &statsView{}
+	e.Subscribe(notify.Sound(cfg, player, sv.today))
+	if log != nil {
+		e.Subscribe(notify.Log(log))
+	}
+
 	return Model{
 		engine: e,
+		stats:  sv,
+		clock:  clk,
 		keys:   newKeyMap(),
 		cfg:    cfg,
-		player: player,
 		logger: log,
+		input:  newPromptInput(),
 		width:  60,
 		height: 20,
 	}
 }
 
-func (m Model) Init() tea.Cmd {
-	return tickCmd()
+// Engine returns the timer engine so that callers can subscribe to its
+// events.
+func (m Model) Engine() *timer.Engine {
+	return m.engine
+}
+
+// WithStateStore makes the model persist the engine on every transition and
+// on quit.
+func (m Model) WithStateStore(s *state.Store) Model {
+	m.state = s
+	return m
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+// WithHistory records every finished session to s and enables the
+// statistics screen, which reads it back.
+func (m Model) WithHistory(s *history.Store) Model {
+	sv := m.stats
+	sv.store, sv.stale = s, true
+	m.engine.Subscribe(s.Handler(func(err error) {
+		m.logError("Writing session history: %v", err)
+	}))
+	m.engine.Subscribe(func(evt timer.Event) {
+		if sessionEnded(evt) {
+			sv.stale = true
+		}
 	})
+	return m
+}
+
+// WithTasks enables the task list panel, backed by src. Completed work
+// sessions count towards the task they were spent on.
+func (m Model) WithTasks(src tasks.Source) Model {
+	p, err := newTaskPanel(src, m.engine)
+	if err != nil {
+		m.logError("Loading tasks: %v", err)
+	}
+	p.list.SetSize(m.width, m.height-6)
+	m.tasks = p
+	m.engine.Subscribe(func(evt timer.Event) {
+		if err := p.handle(evt); err != nil {
+			m.logError("Saving tasks: %v", err)
+		}
+	})
+	return m
+}
+
+// Restore resumes a session saved by a previous run. A task given on the
+// command line replaces the saved one.
+func (m Model) Restore(snap timer.Snapshot) {
+	if m.engine.Restore(snap) {
+		m.log("Restored %s session (cycle %d)", m.engine.Label(), m.engine.Cycle)
+	}
+	if m.cfg.Task != "" {
+		m.engine.SetTask(m.cfg.Task)
+	}
+	if m.tasks != nil {
+		m.tasks.sync()
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.tickCmd()
+}
+
+func (m Model) tickCmd() tea.Cmd {
+	return func() tea.Msg {
+		return tickMsg(<-m.clock.After(time.Second))
+	}
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -54,45 +162,113 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.tasks != nil {
+			m.tasks.list.SetSize(msg.Width, msg.Height-6)
+		}
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.prompt != promptNone {
+			return m.handlePromptKey(msg)
+		}
+		if m.showTasks {
+			return m.handleTaskKey(msg)
+		}
 		return m.handleKey(msg)
 
 	case tickMsg:
 		return m.handleTick()
 	}
 
+	if m.prompt != promptNone {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.Quit):
+		m.saveState()
 		return m, tea.Quit
 
 	case key.Matches(msg, m.keys.Toggle):
-		evt := m.engine.Toggle()
-		if evt == timer.EventStarted {
-			m.playVoiceAsync(m.cfg.Voice.Messages.Start)
-			m.log("Started %s session", m.engine.Mode)
+		m.engine.Toggle()
+		m.saveState()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Finish):
+		m.engine.Finish()
+		m.saveState()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Void):
+		// A session that can be interrupted is one that can be voided.
+		if !m.engine.Interruptible() {
+			return m, nil
+		}
+		return m.openPrompt(promptVoid)
+
+	case key.Matches(msg, m.keys.Lap):
+		m.engine.Lap()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Internal):
+		if !m.engine.Interruptible() {
+			return m, nil
+		}
+		return m.openPrompt(promptInternal)
+
+	case key.Matches(msg, m.keys.External):
+		if !m.engine.Interruptible() {
+			return m, nil
+		}
+		return m.openPrompt(promptExternal)
+
+	case key.Matches(msg, m.keys.Task):
+		if standalone(m.engine) {
+			return m, nil
+		}
+		return m.openPrompt(promptTask)
+
+	case key.Matches(msg, m.keys.Tasks):
+		if m.tasks == nil || standalone(m.engine) {
+			return m, nil
+		}
+		m.showTasks, m.showStats = true, false
+		return m, nil
+
+	case key.Matches(msg, m.keys.Cancel):
+		if m.engine.CancelAutoStart() {
+			m.log("Cancelled auto-start of %s", m.engine.Mode)
 		}
 		return m, nil
 
 	case key.Matches(msg, m.keys.Reset):
 		m.engine.Reset()
-		m.log("Reset %s session", m.engine.Mode)
+		m.saveState()
 		return m, nil
 
 	case key.Matches(msg, m.keys.Skip):
-		evt := m.engine.Skip()
-		m.handleEvent(evt)
-		m.log("Skipped to %s", m.engine.Mode)
+		m.engine.Skip()
+		m.saveState()
 		return m, nil
 
 	case key.Matches(msg, m.keys.Config):
 		return m, m.openConfig()
 
+	case key.Matches(msg, m.keys.Stats):
+		if m.stats.store == nil {
+			return m, nil
+		}
+		m.showStats = !m.showStats
+		if m.showStats {
+			m.stats.refresh()
+		}
+		return m, nil
+
 	case key.Matches(msg, m.keys.TimeUp):
 		m.engine.AdjustTime(time.Minute)
 		return m, nil
@@ -114,43 +290,24 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleTick() (tea.Model, tea.Cmd) {
-	evt := m.engine.Tick()
-
-	switch evt {
-	case timer.EventTick:
-		if m.cfg.Sounds.Tick {
-			go m.player.PlayBeep(context.Background())
-		}
+	switch m.engine.Tick().Type {
+	case timer.EventTick, timer.EventNone:
 	default:
-		m.handleEvent(evt)
+		m.saveState()
+	}
+	if m.showStats {
+		m.stats.refresh()
 	}
 
-	return m, tickCmd()
+	return m, m.tickCmd()
 }
 
-func (m Model) handleEvent(evt timer.Event) {
-	ctx := context.Background()
-
-	switch evt {
-	case timer.EventWorkDone:
-		m.log("Work session completed (cycle %d)", m.engine.Cycle)
-		if m.cfg.Sounds.Finish {
-			go m.player.PlayBeep(ctx)
-		}
-		m.playVoiceAsync(m.cfg.Voice.Messages.WorkDone)
-
-	case timer.EventBreakDone:
-		m.log("Break completed, starting work")
-		if m.cfg.Sounds.Break {
-			go m.player.PlayBeep(ctx)
-		}
-		m.playVoiceAsync(m.cfg.Voice.Messages.BreakDone)
+func (m Model) saveState() {
+	if m.state == nil {
+		return
 	}
-}
-
-func (m Model) playVoiceAsync(message string) {
-	if m.cfg.Voice.Enabled && message != "" {
-		go m.player.PlayVoice(context.Background(), m.cfg.Voice.Voice, message)
+	if err := m.state.Save(m.engine.Snapshot()); err != nil {
+		m.logError("Saving state: %v", err)
 	}
 }
 
@@ -160,6 +317,12 @@ func (m Model) log(format string, args ...any) {
 	}
 }
 
+func (m Model) logError(format string, args ...any) {
+	if m.logger != nil {
+		m.logger.Error(format, args...)
+	}
+}
+
 func (m Model) openConfig() tea.Cmd {
 	return func() tea.Msg {
 		editor := os.Getenv("EDITOR")
@@ -180,5 +343,17 @@ func (m Model) openConfig() tea.Cmd {
 }
 
 func (m Model) View() string {
-	return "\n" + renderView(m.engine, m.width) + "\n"
+	var v string
+	switch {
+	case m.showTasks:
+		v = "\n" + renderTasks(m.tasks, m.engine, m.width) + "\n"
+	case m.showStats:
+		v = "\n" + renderStats(m.stats, m.clock.Now(), m.width) + "\n"
+	default:
+		v = "\n" + renderView(m.engine, m.width) + "\n"
+	}
+	if m.prompt != promptNone {
+		v += "\n" + renderPrompt(m.prompt, m.input, m.width) + "\n"
+	}
+	return v
 }
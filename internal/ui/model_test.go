@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/and1truong/tui-timer/internal/clock/clocktest"
+	"github.com/and1truong/tui-timer/internal/config"
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/sound"
+	"github.com/and1truong/tui-timer/internal/tasks"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+func newTestModel(t *testing.T) (Model, *clocktest.Fake) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Sounds = config.SoundsConfig{}
+	cfg.Voice.Enabled = false
+	clk := clocktest.NewFake(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	return NewModel(cfg, &sound.NoopPlayer{}, nil, clk), clk
+}
+
+func press(m Model, k string) Model {
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	return next.(Model)
+}
+
+func TestTickCmdWaitsForClock(t *testing.T) {
+	m, clk := newTestModel(t)
+
+	got := make(chan tea.Msg, 1)
+	go func() { got <- m.Init()() }()
+
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-got:
+		t.Fatal("tick fired before the clock advanced")
+	default:
+	}
+
+	clk.Advance(time.Second)
+	if _, ok := (<-got).(tickMsg); !ok {
+		t.Fatal("expected tickMsg")
+	}
+}
+
+func TestModelCompletesWorkSession(t *testing.T) {
+	m, clk := newTestModel(t)
+	m = press(m, " ")
+
+	clk.Advance(25 * time.Minute)
+	next, cmd := m.Update(tickMsg(clk.Now()))
+	m = next.(Model)
+
+	if m.engine.Mode != timer.ModeShortBreak {
+		t.Errorf("expected ModeShortBreak, got %v", m.engine.Mode)
+	}
+	if m.engine.Cycle != 1 {
+		t.Errorf("expected cycle 1, got %d", m.engine.Cycle)
+	}
+	if cmd == nil {
+		t.Error("expected the next tick to be scheduled")
+	}
+}
+
+func TestModelAutoStartCancel(t *testing.T) {
+	m, clk := newTestModel(t)
+	m.engine.AutoStartBreaks = true
+	m.engine.AutoStartDelay = 5 * time.Second
+	m = press(m, " ")
+
+	clk.Advance(25 * time.Minute)
+	next, _ := m.Update(tickMsg(clk.Now()))
+	m = next.(Model)
+	if _, ok := m.engine.AutoStartIn(); !ok {
+		t.Fatal("expected a pending auto-start")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	clk.Advance(10 * time.Second)
+	next, _ = m.Update(tickMsg(clk.Now()))
+	m = next.(Model)
+
+	if m.engine.State != timer.StateIdle {
+		t.Errorf("expected break to stay idle after cancel, got %v", m.engine.State)
+	}
+}
+
+func TestModelInterruptionPrompt(t *testing.T) {
+	m, _ := newTestModel(t)
+
+	m = press(m, "i")
+	if m.prompt != promptNone {
+		t.Fatal("expected no prompt before the session starts")
+	}
+
+	m = press(m, " ")
+	m = press(m, "e")
+	if m.prompt != promptExternal {
+		t.Fatalf("expected external prompt, got %v", m.prompt)
+	}
+	m = press(m, "phone")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	if m.prompt != promptNone {
+		t.Error("expected prompt to close on enter")
+	}
+	if len(m.engine.Interruptions) != 1 {
+		t.Fatalf("expected 1 interruption, got %d", len(m.engine.Interruptions))
+	}
+	if in := m.engine.Interruptions[0]; in.Kind != timer.InterruptExternal || in.Note != "phone" {
+		t.Errorf("unexpected interruption %+v", in)
+	}
+
+	m = press(m, "i")
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if len(m.engine.Interruptions) != 1 {
+		t.Error("expected esc to cancel the interruption")
+	}
+}
+
+func TestModelVoidDoesNotCount(t *testing.T) {
+	m, clk := newTestModel(t)
+	m = press(m, " ")
+	clk.Advance(10 * time.Minute)
+
+	m = press(m, "v")
+	if m.prompt != promptVoid {
+		t.Fatalf("expected void prompt, got %v", m.prompt)
+	}
+	m = press(m, "meeting")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	if m.engine.Cycle != 0 || m.engine.Mode != timer.ModeWork || m.engine.State != timer.StateIdle {
+		t.Errorf("expected an uncounted fresh work session, got cycle %d %v/%v", m.engine.Cycle, m.engine.Mode, m.engine.State)
+	}
+}
+
+func TestSessionEnded(t *testing.T) {
+	for _, typ := range []timer.EventType{timer.EventWorkDone, timer.EventFinished, timer.EventVoided} {
+		if !sessionEnded(timer.Event{Type: typ}) {
+			t.Errorf("expected %v to mark the stats stale", typ)
+		}
+	}
+	if sessionEnded(timer.Event{Type: timer.EventTick}) {
+		t.Error("expected ticks not to mark the stats stale")
+	}
+}
+
+func TestModelStatsScreen(t *testing.T) {
+	m, clk := newTestModel(t)
+	store := history.NewStore(filepath.Join(t.TempDir(), "sessions.jsonl"))
+	m = m.WithHistory(store)
+
+	m = press(m, "h")
+	if !m.showStats {
+		t.Fatal("expected the stats screen")
+	}
+	if v := m.View(); !strings.Contains(v, "Today: 0 pomodoros") || !strings.Contains(v, "Streak: 0 days") {
+		t.Errorf("expected empty stats, got:\n%s", v)
+	}
+
+	// The timer keeps running behind the stats screen.
+	m = press(m, " ")
+	clk.Advance(25 * time.Minute)
+	next, _ := m.Update(tickMsg(clk.Now()))
+	m = next.(Model)
+
+	v := m.View()
+	if !strings.Contains(v, "Today: 1 pomodoro ") || !strings.Contains(v, "25m focus") || !strings.Contains(v, "Streak: 1 day") {
+		t.Errorf("expected stats to refresh after the session, got:\n%s", v)
+	}
+
+	m = press(m, "h")
+	if m.showStats {
+		t.Error("expected h to return to the timer")
+	}
+}
+
+func TestModelTaskPrompt(t *testing.T) {
+	m, clk := newTestModel(t)
+	m.cfg.Task = "Client A"
+	m.engine.Task = m.cfg.Task
+
+	m = press(m, "t")
+	if m.prompt != promptTask || m.input.Value() != "Client A" {
+		t.Fatalf("expected task prompt prefilled with the current task, got %v %q", m.prompt, m.input.Value())
+	}
+	m.input.SetValue("Invoicing")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	if m.engine.Task != "Invoicing" {
+		t.Errorf("expected task Invoicing, got %q", m.engine.Task)
+	}
+	if !strings.Contains(m.View(), "Invoicing") {
+		t.Error("expected the task in the header")
+	}
+
+	// A task from the command line wins over the saved session's.
+	m.Restore(timer.Snapshot{Kind: timer.KindPomodoro, Task: "Old", SavedAt: clk.Now()})
+	if m.engine.Task != "Client A" {
+		t.Errorf("expected the --task value after restore, got %q", m.engine.Task)
+	}
+}
+
+func TestModelTaskPanel(t *testing.T) {
+	m, clk := newTestModel(t)
+	store := tasks.NewStore(filepath.Join(t.TempDir(), "tasks.json"))
+	m = m.WithTasks(store)
+
+	m = press(m, "p")
+	if !m.showTasks {
+		t.Fatal("expected the task panel")
+	}
+	for _, title := range []string{"Write report", "Email"} {
+		m = press(m, "a")
+		m.input.SetValue(title)
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(Model)
+	}
+
+	// Email is selected after adding it: estimate it, move it to the top.
+	m = press(m, "+")
+	m = press(m, "+")
+	m = press(m, "K")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	if m.showTasks || m.engine.Task != "Email" {
+		t.Fatalf("expected to work on Email, got %q (panel %v)", m.engine.Task, m.showTasks)
+	}
+
+	m = press(m, " ")
+	clk.Advance(25 * time.Minute)
+	next, _ = m.Update(tickMsg(clk.Now()))
+	m = next.(Model)
+
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []tasks.Task{
+		{Title: "Email", Estimate: 2, Actual: 1},
+		{Title: "Write report"},
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("expected %+v, got %+v", want, saved)
+	}
+
+	// The last work session of a one-shot plan counts too.
+	for _, evt := range []timer.Event{
+		{Type: timer.EventFinished, Kind: timer.KindPomodoro, Mode: timer.ModeWork, Task: "Email"},
+		{Type: timer.EventFinished, Kind: timer.KindCountdown, Mode: timer.ModeWork, Task: "Email"},
+	} {
+		if err := m.tasks.handle(evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if saved, _ := store.Load(); saved[0].Actual != 2 {
+		t.Errorf("expected the plan's last session to count once, got %d", saved[0].Actual)
+	}
+
+	// Completing the active task clears it from the engine.
+	m = press(m, "p")
+	m = press(m, "x")
+	if m.engine.Task != "" {
+		t.Errorf("expected the task to clear, got %q", m.engine.Task)
+	}
+	if saved, _ := store.Load(); !saved[0].Done {
+		t.Error("expected Email to be done")
+	}
+}
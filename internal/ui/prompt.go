@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/and1truong/tui-timer/internal/tasks"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+// promptKind identifies what the text prompt is collecting.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptInternal
+	promptExternal
+	promptVoid
+	promptTask
+	promptAddTask
+)
+
+func (k promptKind) title() string {
+	switch k {
+	case promptInternal:
+		return "Internal interruption – note (optional)"
+	case promptExternal:
+		return "External interruption – note (optional)"
+	case promptVoid:
+		return "Void this session – reason (optional)"
+	case promptTask:
+		return "Task – what are you working on? (empty to clear)"
+	case promptAddTask:
+		return "New task"
+	default:
+		return ""
+	}
+}
+
+func newPromptInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 80
+	ti.Width = 40
+	ti.Prompt = "> "
+	return ti
+}
+
+func (m Model) openPrompt(kind promptKind) (tea.Model, tea.Cmd) {
+	m.prompt = kind
+	m.input.Reset()
+	if kind == promptTask {
+		m.input.SetValue(m.engine.Task)
+	}
+	return m, m.input.Focus()
+}
+
+func (m Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.saveState()
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.closePrompt()
+		return m, nil
+	case tea.KeyEnter:
+		kind, value := m.prompt, strings.TrimSpace(m.input.Value())
+		m.closePrompt()
+		m.submitPrompt(kind, value)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) closePrompt() {
+	m.prompt = promptNone
+	m.input.Blur()
+}
+
+func (m Model) submitPrompt(kind promptKind, value string) {
+	switch kind {
+	case promptInternal:
+		m.engine.Interrupt(timer.InterruptInternal, value)
+		m.saveState()
+	case promptExternal:
+		m.engine.Interrupt(timer.InterruptExternal, value)
+		m.saveState()
+	case promptVoid:
+		m.engine.Void(value)
+		m.saveState()
+	case promptTask:
+		m.engine.SetTask(value)
+		m.saveState()
+	case promptAddTask:
+		p := m.tasks
+		if err := p.update(func() { p.tasks = tasks.Add(p.tasks, value) }); err != nil {
+			m.logError("Saving tasks: %v", err)
+		}
+		p.list.Select(len(p.tasks) - 1)
+	}
+}
+
+func renderPrompt(kind promptKind, input textinput.Model, width int) string {
+	title := stateStyle.Render(kind.title())
+	hint := hintStyle.Render("enter: save  |  esc: cancel")
+	body := lipgloss.JoinVertical(lipgloss.Left, title, input.View(), hint)
+	return lipgloss.PlaceHorizontal(width, lipgloss.Center, body)
+}
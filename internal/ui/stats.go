@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/and1truong/tui-timer/internal/history"
+	"github.com/and1truong/tui-timer/internal/stats"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+// heatWeeks is the most weeks of history the heatmap shows.
+const heatWeeks = 26
+
+// heatColors shade heatmap cells from no focus to a lot of it.
+var heatColors = []lipgloss.Color{"238", "22", "28", "34", "46"}
+
+// statsView backs the in-app statistics screen and the day's totals in voice
+// messages. It is shared by every copy of the Model so that engine handlers
+// can mark it stale; store is nil when there is no history.
+type statsView struct {
+	store   *history.Store
+	records []history.Record
+	stale   bool
+	err     error
+}
+
+// sessionEnded reports whether evt appends a record to the history.
+func sessionEnded(evt timer.Event) bool {
+	switch evt.Type {
+	case timer.EventWorkDone, timer.EventBreakDone, timer.EventCompletedEarly,
+		timer.EventVoided, timer.EventSkipped, timer.EventFinished:
+		return true
+	}
+	return false
+}
+
+func (s *statsView) refresh() {
+	if !s.stale {
+		return
+	}
+	s.records, _, s.err = s.store.Load()
+	s.stale = false
+}
+
+// today returns the focus time and pomodoros recorded today, for voice
+// messages. Both are zero without a history.
+func (s *statsView) today(now time.Time) (time.Duration, int) {
+	if s.store == nil {
+		return 0, 0
+	}
+	s.refresh()
+	start := stats.PeriodStart(now, stats.Day)
+	sum := stats.Total(stats.Filter(s.records, start, start.AddDate(0, 0, 1)))
+	return sum.Focus, sum.Pomodoros
+}
+
+func renderStats(s *statsView, now time.Time, width int) string {
+	var b strings.Builder
+	center := lipgloss.NewStyle().Width(width).Align(lipgloss.Center)
+
+	b.WriteString(titleStyle.Width(width).Render("Statistics"))
+	b.WriteString("\n\n")
+
+	if s.err != nil {
+		b.WriteString(center.Render(stateStyle.Render(fmt.Sprintf("could not read history: %v", s.err))))
+		b.WriteString("\n\n")
+	}
+
+	dayStart := stats.PeriodStart(now, stats.Day)
+	today := stats.Total(stats.Filter(s.records, dayStart, dayStart.AddDate(0, 0, 1)))
+	daily := stats.DailyFocus(s.records, now.Location())
+	streak := stats.Streak(daily, now)
+
+	summary := fmt.Sprintf("Today: %s  ·  %s focus  ·  Streak: %s",
+		plural(today.Pomodoros, "pomodoro"), formatFocus(today.Focus), plural(streak, "day"))
+	b.WriteString(center.Render(modeWorkStyle.Render(summary)))
+	b.WriteString("\n\n")
+
+	weeks := (width - 6) / 2
+	if weeks > heatWeeks {
+		weeks = heatWeeks
+	}
+	if weeks < 4 {
+		weeks = 4
+	}
+	b.WriteString(center.Render(renderHeatmap(daily, now, weeks)))
+	b.WriteString("\n\n")
+
+	b.WriteString(hintStyle.Width(width).Render("h: back to timer  |  q: quit"))
+	return b.String()
+}
+
+// renderHeatmap draws daily focus time as a calendar, one column per week
+// ending with the current one and one row per weekday.
+func renderHeatmap(daily map[time.Time]time.Duration, now time.Time, weeks int) string {
+	today := stats.PeriodStart(now, stats.Day)
+	first := stats.PeriodStart(now, stats.Week).AddDate(0, 0, -7*(weeks-1))
+
+	// Month labels above the first column of each month.
+	months := []rune(strings.Repeat(" ", 4+2*weeks))
+	last := -4
+	for w := 0; w < weeks; w++ {
+		start := first.AddDate(0, 0, 7*w)
+		if w > 0 && start.Month() == start.AddDate(0, 0, -7).Month() {
+			continue
+		}
+		col := 4 + 2*w
+		if col-last < 4 || col+3 > len(months) {
+			continue
+		}
+		copy(months[col:], []rune(start.Format("Jan")))
+		last = col
+	}
+
+	lines := []string{stateStyle.Render(string(months))}
+	labels := []string{"Mon", "", "Wed", "", "Fri", "", "Sun"}
+	for d := 0; d < 7; d++ {
+		var row strings.Builder
+		row.WriteString(stateStyle.Render(fmt.Sprintf("%-3s ", labels[d])))
+		for w := 0; w < weeks; w++ {
+			day := first.AddDate(0, 0, 7*w+d)
+			if day.After(today) {
+				row.WriteString("  ")
+				continue
+			}
+			row.WriteString(heatCell(daily[day]) + " ")
+		}
+		lines = append(lines, row.String())
+	}
+
+	legend := stateStyle.Render("less ")
+	for _, c := range heatColors {
+		legend += lipgloss.NewStyle().Foreground(c).Render("■") + " "
+	}
+	lines = append(lines, "", legend+stateStyle.Render("more"))
+	return strings.Join(lines, "\n")
+}
+
+func heatCell(d time.Duration) string {
+	level := 0
+	switch {
+	case d >= 2*time.Hour:
+		level = 4
+	case d >= time.Hour:
+		level = 3
+	case d >= 30*time.Minute:
+		level = 2
+	case d > 0:
+		level = 1
+	}
+	return lipgloss.NewStyle().Foreground(heatColors[level]).Render("■")
+}
+
+func formatFocus(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
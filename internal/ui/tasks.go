@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/and1truong/tui-timer/internal/tasks"
+	"github.com/and1truong/tui-timer/internal/timer"
+)
+
+// taskPanel backs the task list pane. Like statsView it is shared by every
+// copy of the Model so that engine handlers can count pomodoros on it.
+type taskPanel struct {
+	source tasks.Source
+	tasks  []tasks.Task
+	engine *timer.Engine // whose task is marked active
+	list   list.Model
+}
+
+// taskItem adapts a task to the bubbles list.
+type taskItem struct {
+	task   tasks.Task
+	active bool
+}
+
+func (i taskItem) Title() string {
+	switch {
+	case i.task.Done:
+		return "✓ " + i.task.Title
+	case i.active:
+		return "▶ " + i.task.Title
+	default:
+		return "  " + i.task.Title
+	}
+}
+
+func (i taskItem) Description() string {
+	d := fmt.Sprintf("  %d pomodoros", i.task.Actual)
+	if i.task.Estimate > 0 {
+		d = fmt.Sprintf("  %d/%d pomodoros", i.task.Actual, i.task.Estimate)
+	}
+	if i.task.Over() {
+		d += "  · over estimate"
+	}
+	return d
+}
+
+func (i taskItem) FilterValue() string { return i.task.Title }
+
+func newTaskPanel(src tasks.Source, e *timer.Engine) (*taskPanel, error) {
+	l := list.New(nil, list.NewDefaultDelegate(), 60, 14)
+	l.Title = "Tasks"
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+
+	p := &taskPanel{source: src, engine: e, list: l}
+	var err error
+	p.tasks, err = src.Load()
+	p.sync()
+	return p, err
+}
+
+// sync rebuilds the list items from p.tasks, keeping the selection.
+func (p *taskPanel) sync() {
+	items := make([]list.Item, len(p.tasks))
+	activeIdx := tasks.Find(p.tasks, p.engine.Task)
+	for i, t := range p.tasks {
+		items[i] = taskItem{task: t, active: i == activeIdx}
+	}
+	p.list.SetItems(items)
+}
+
+func (p *taskPanel) selected() (int, bool) {
+	i := p.list.Index()
+	return i, len(p.tasks) > 0 && i < len(p.tasks)
+}
+
+// update applies fn to the task list, then saves and redraws it. A list
+// that was changed elsewhere is loaded again.
+func (p *taskPanel) update(fn func()) error {
+	fn()
+	err := p.source.Save(p.tasks)
+	if errors.Is(err, tasks.ErrChanged) {
+		p.tasks, err = p.source.Load()
+	}
+	p.sync()
+	return err
+}
+
+// handle keeps the panel in step with the engine: it marks the engine's
+// task and counts its completed work sessions.
+func (p *taskPanel) handle(evt timer.Event) error {
+	switch evt.Type {
+	case timer.EventTaskChanged:
+		p.sync()
+	case timer.EventWorkDone, timer.EventCompletedEarly, timer.EventFinished:
+		// A one-shot plan ends on a work session; standalone timers do
+		// not count, as in the history.
+		i := tasks.Find(p.tasks, evt.Task)
+		if evt.Mode != timer.ModeWork || evt.Kind == timer.KindCountdown || evt.Kind == timer.KindStopwatch || i < 0 {
+			return nil
+		}
+		return p.update(func() { p.tasks[i].Actual++ })
+	}
+	return nil
+}
+
+func (m Model) handleTaskKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.tasks
+	i, ok := p.selected()
+	var err error
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.saveState()
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Tasks), msg.Type == tea.KeyEsc:
+		m.showTasks = false
+		return m, nil
+
+	case key.Matches(msg, m.keys.TaskAdd):
+		return m.openPrompt(promptAddTask)
+
+	case key.Matches(msg, m.keys.TaskSelect):
+		if ok && !p.tasks[i].Done {
+			m.engine.SetTask(p.tasks[i].Title)
+			m.saveState()
+			m.showTasks = false
+		}
+		return m, nil
+
+	case !ok:
+		// The remaining keys act on the selected task.
+
+	case key.Matches(msg, m.keys.TaskDone):
+		t := p.tasks[i]
+		err = p.update(func() { p.tasks[i].Done = !t.Done })
+		if !t.Done && t.Title == m.engine.Task {
+			m.engine.SetTask("")
+			m.saveState()
+		}
+
+	case key.Matches(msg, m.keys.TaskDelete):
+		err = p.update(func() { p.tasks = append(p.tasks[:i], p.tasks[i+1:]...) })
+
+	case key.Matches(msg, m.keys.EstimateUp):
+		err = p.update(func() { p.tasks[i].Estimate++ })
+
+	case key.Matches(msg, m.keys.EstimateDown):
+		if p.tasks[i].Estimate > 0 {
+			err = p.update(func() { p.tasks[i].Estimate-- })
+		}
+
+	case key.Matches(msg, m.keys.MoveUp), key.Matches(msg, m.keys.MoveDown):
+		delta := 1
+		if key.Matches(msg, m.keys.MoveUp) {
+			delta = -1
+		}
+		var j int
+		err = p.update(func() { j = tasks.Move(p.tasks, i, delta) })
+		p.list.Select(j)
+
+	default:
+		var cmd tea.Cmd
+		p.list, cmd = p.list.Update(msg)
+		return m, cmd
+	}
+
+	if err != nil {
+		m.logError("Saving tasks: %v", err)
+	}
+	return m, nil
+}
+
+func renderTasks(p *taskPanel, e *timer.Engine, width int) string {
+	status := fmt.Sprintf("%s  %s", renderMode(e), timerStyle.Render(formatDuration(e.Remaining)))
+	if e.CountsUp() {
+		status = fmt.Sprintf("%s  %s", renderMode(e), timerStyle.Render("+"+formatDuration(e.Elapsed())))
+	}
+	hints := "↑/↓: move  |  enter: work on  |  a: add  |  x: done  |  +/-: estimate  |  K/J: reorder  |  d: delete  |  esc: back"
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(status),
+		"",
+		p.list.View(),
+		"",
+		hintStyle.Width(width).Render(hints),
+	)
+}
@@ -41,6 +41,14 @@ var (
 	stateStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245")).
 			Italic(true)
+
+	taskStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("117")).
+			Align(lipgloss.Center)
+
+	tallyStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("203"))
 )
 
 func renderView(e *timer.Engine, width int) string {
@@ -50,33 +58,109 @@ func renderView(e *timer.Engine, width int) string {
 	modeStr := renderMode(e)
 	cycleStr := fmt.Sprintf("Cycle: %d", e.Cycle)
 	topLine := fmt.Sprintf("%s  |  %s", modeStr, cycleStr)
+	if n := len(e.Sequence); n > 0 {
+		topLine = fmt.Sprintf("%s  |  Step %d/%d  |  %s", modeStr, e.Step+1, n, cycleStr)
+	}
+	if standalone(e) {
+		topLine = modeStr
+	}
 	b.WriteString(titleStyle.Width(width).Render(topLine))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if e.Task != "" && !standalone(e) {
+		b.WriteString(taskStyle.Width(width).Render("⚑ " + e.Task))
+	}
+	b.WriteString("\n")
 
 	// State indicator
 	stateStr := renderState(e.State)
+	if in, ok := e.AutoStartIn(); ok {
+		stateStr = stateStyle.Render(fmt.Sprintf("⏵ Starting in %ds  (esc to cancel)", int(in.Round(time.Second).Seconds())))
+	}
+	if len(e.Interruptions) > 0 {
+		stateStr += "   " + renderTally(e.Interruptions)
+	}
 	b.WriteString(lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(stateStr))
 	b.WriteString("\n\n")
 
 	// Center: big timer
 	timeStr := formatDuration(e.Remaining)
+	if e.CountsUp() {
+		timeStr = "+" + formatDuration(e.Elapsed())
+	}
 	b.WriteString(timerStyle.Width(width).Render(timeStr))
 	b.WriteString("\n\n")
 
-	// Progress bar
+	// Progress bar; laps for a stopwatch, the break earned so far for Flowtime
 	bar := renderProgressBar(e.Progress(), width-10)
+	switch {
+	case e.Kind == timer.KindStopwatch:
+		bar = renderLaps(e.Laps, maxLaps)
+	case e.CountsUp():
+		bar = stateStyle.Render(fmt.Sprintf("break earned: %s", formatDuration(e.FlowPolicy.Break(e.Elapsed()))))
+	}
 	b.WriteString(lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(bar))
 	b.WriteString("\n\n")
 
 	// Bottom: key hints
-	hints := "space: start/pause  |  r: reset  |  s: skip  |  c: config  |  q: quit"
+	hints := "space: start/pause  |  enter: done early  |  v: void  |  i/e: interruption  |  t/p: task/list  |  s: skip break  |  r: reset  |  h: stats  |  c: config  |  q: quit"
+	switch e.Kind {
+	case timer.KindFlowtime:
+		hints = "space: start/pause  |  enter: finish  |  v: void  |  i/e: interruption  |  t/p: task/list  |  s: skip break  |  r: reset  |  h: stats  |  q: quit"
+	case timer.KindCountdown:
+		hints = "space: start/pause  |  r: reset  |  q: quit"
+	case timer.KindStopwatch:
+		hints = "space: start/pause  |  l: lap  |  r: reset  |  q: quit"
+	}
 	b.WriteString(hintStyle.Width(width).Render(hints))
 
 	return b.String()
 }
 
+// maxLaps is how many of the most recent laps the stopwatch lists.
+const maxLaps = 5
+
+// standalone reports whether e is a plain timer without Pomodoro cycles.
+func standalone(e *timer.Engine) bool {
+	return e.Kind == timer.KindCountdown || e.Kind == timer.KindStopwatch
+}
+
+func renderLaps(laps []time.Duration, limit int) string {
+	if len(laps) == 0 {
+		return stateStyle.Render("no laps")
+	}
+	start := 0
+	if len(laps) > limit {
+		start = len(laps) - limit
+	}
+	lines := make([]string, 0, limit)
+	for i := len(laps) - 1; i >= start; i-- {
+		lines = append(lines, fmt.Sprintf("Lap %2d  %s", i+1, formatLap(laps[i])))
+	}
+	return stateStyle.Render(strings.Join(lines, "\n"))
+}
+
+func formatLap(d time.Duration) string {
+	cs := int(d.Milliseconds()/10) % 100
+	return fmt.Sprintf("%s.%02d", formatDuration(d), cs)
+}
+
+// renderTally marks interruptions the Pomodoro way: ' for internal and
+// - for external.
+func renderTally(in []timer.Interruption) string {
+	var internal, external int
+	for _, i := range in {
+		if i.Kind == timer.InterruptExternal {
+			external++
+		} else {
+			internal++
+		}
+	}
+	marks := strings.TrimSpace(strings.Repeat("' ", internal) + strings.Repeat("- ", external))
+	return tallyStyle.Render(marks)
+}
+
 func renderMode(e *timer.Engine) string {
-	label := e.Mode.String()
+	label := e.Label()
 	switch e.Mode {
 	case timer.ModeWork:
 		return modeWorkStyle.Render(label)